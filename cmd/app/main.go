@@ -12,25 +12,9 @@ import (
 	"syscall"
 	"time"
 
-	"marketplace/internal/adapter/bcrypt"
-	"marketplace/internal/adapter/jwt"
-	"marketplace/internal/adapter/postgres/customer"
-	productAdapter "marketplace/internal/adapter/postgres/product"
-	"marketplace/internal/adapter/postgres/seller"
-	"marketplace/internal/adapter/postgres/token"
-	"marketplace/internal/adapter/postgres/user"
-	"marketplace/internal/handler/auth"
-	"marketplace/internal/handler/product"
-	usecase "marketplace/internal/usecase/auth"
-	usecaseProduct "marketplace/internal/usecase/product"
 	"marketplace/pkg/config"
 	adapter "marketplace/pkg/pgxpool"
 
-	"github.com/go-playground/validator/v10"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-
-	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
@@ -38,6 +22,8 @@ import (
 func main() {
 	// Флаги для config
 	configPath := flag.String("config", "config.yaml", "path to config file")
+	runMigrations := flag.Bool("migrate", false, "apply pending migrations on startup")
+	skipMigrateCheck := flag.Bool("skip-migrate-check", false, "start without verifying the schema version")
 	flag.Parse()
 
 	// Инициализация Viper
@@ -45,6 +31,7 @@ func main() {
 	viper.AddConfigPath(".")
 	viper.SetEnvPrefix("app")
 	viper.AutomaticEnv()
+	config.SetDefaults()
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("failed to read config: %v", err)
@@ -54,6 +41,18 @@ func main() {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		log.Fatalf("failed to unmarshal config: %v", err)
 	}
+	if err := cfg.CORS.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	if err := cfg.DeletePolicy.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	if err := cfg.ValidateTimeouts(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	if err := cfg.JWT.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
 
 	// Инициализация logrus напрямую
 	rawLogger := logrus.New()
@@ -61,6 +60,13 @@ func main() {
 		FullTimestamp: true,
 	})
 	rawLogger.SetLevel(logrus.InfoLevel)
+	rawLogger.WithField("features", cfg.Features.EnabledNames()).Info("enabled features")
+
+	if *skipMigrateCheck {
+		rawLogger.Warn("schema version check skipped")
+	} else if err := adapter.EnsureMigrated(adapter.BuildDSN(&cfg), cfg.DB.MigrationsPath, *runMigrations, rawLogger); err != nil {
+		rawLogger.Fatalf("database schema check failed: %v", err)
+	}
 
 	ctx := context.Background()
 	pool, err := adapter.InitDBPool(ctx, &cfg, rawLogger)
@@ -69,48 +75,24 @@ func main() {
 	}
 	defer pool.Close()
 
-	// Репозитории
-	userRepo := user.NewUserRepository(pool, rawLogger)
-	customerRepo := customer.NewCustomerRepository(pool, rawLogger)
-	sellerRepo := seller.NewSellerRepository(pool, rawLogger)
-	tokenRepo := token.NewTokenRepository(pool, rawLogger)
-	productRepo := productAdapter.NewProductRepository(pool, rawLogger)
-
-	// Менеджеры
-	bcryptManager := bcrypt.NewBcryptManager(rawLogger, 12)
-	jwtManager := jwt.NewJWTManager(tokenRepo, rawLogger, cfg)
-
-	// Usecase
-	authUsecase := usecase.NewAuthUsecase(userRepo, customerRepo, sellerRepo, tokenRepo, jwtManager, bcryptManager, rawLogger)
-	productUsecase := usecaseProduct.NewProductUsecase(productRepo, rawLogger, validator.New())
-
-	// Handler
-	authHandler := auth.NewAuthHandler(authUsecase, rawLogger)
-	productHandler := product.NewProductHandler(productUsecase, rawLogger)
-
-	// Gin router
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(gin.Logger())
-
-	// Группа маршрутов
-	apiGroup := r.Group("/")
-	auth.RegisterAuthRoutes(apiGroup, authHandler, jwtManager, rawLogger)
-	r.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "alive"})
-	})
-	product.RegisterProductRoutes(apiGroup, productHandler, jwtManager, rawLogger)
-	r.POST("/test", func(c *gin.Context) {
-		var data map[string]interface{}
-		c.BindJSON(&data)
-		c.JSON(http.StatusOK, gin.H{"success": true, "received": data})
-	})
+	r, views := newRouter(cfg, pool, rawLogger)
+	viewsCtx, stopViews := context.WithCancel(ctx)
+	viewsDone := make(chan struct{})
+	go func() {
+		views.Run(viewsCtx)
+		close(viewsDone)
+	}()
 
 	// HTTP server
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: r,
+		Addr:              addr,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
 
 	go func() {
@@ -134,5 +116,9 @@ func main() {
 		rawLogger.Fatalf("server shutdown failed: %v", err)
 	}
 
+	// Write the views buffered since the last flush before the pool closes.
+	stopViews()
+	<-viewsDone
+
 	rawLogger.Info("server exited gracefully")
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"marketplace/internal/adapter/bcrypt"
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/adapter/mailer"
+	apiTokenAdapter "marketplace/internal/adapter/postgres/apitoken"
+	auditAdapter "marketplace/internal/adapter/postgres/audit"
+	categoryAdapter "marketplace/internal/adapter/postgres/category"
+	"marketplace/internal/adapter/postgres/customer"
+	"marketplace/internal/adapter/postgres/passwordhistory"
+	productAdapter "marketplace/internal/adapter/postgres/product"
+	productImageAdapter "marketplace/internal/adapter/postgres/product_image"
+	sellerAdapter "marketplace/internal/adapter/postgres/seller"
+	"marketplace/internal/adapter/postgres/token"
+	"marketplace/internal/adapter/postgres/user"
+	"marketplace/internal/adapter/postgres/verification"
+	"marketplace/internal/handler/admin"
+	"marketplace/internal/handler/auth"
+	"marketplace/internal/handler/category"
+	"marketplace/internal/handler/images"
+	"marketplace/internal/handler/middleware"
+	"marketplace/internal/handler/product"
+	"marketplace/internal/handler/response"
+	"marketplace/internal/handler/seller"
+	usecaseAdmin "marketplace/internal/usecase/admin"
+	usecaseAPIToken "marketplace/internal/usecase/apitoken"
+	usecase "marketplace/internal/usecase/auth"
+	usecaseCategory "marketplace/internal/usecase/category"
+	usecaseImages "marketplace/internal/usecase/images"
+	usecaseProduct "marketplace/internal/usecase/product"
+	"marketplace/pkg/config"
+	"marketplace/pkg/money"
+	adapter "marketplace/pkg/pgxpool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// newRouter wires repositories, usecases and handlers on top of pool and
+// registers every route. The returned view counter is not running yet; the
+// caller owns its Run loop so buffered views are flushed before pool closes.
+func newRouter(cfg config.Config, pool *pgxpool.Pool, rawLogger *logrus.Logger) (*gin.Engine, *usecaseProduct.ViewCounter) {
+	// Репозитории
+	userRepo := user.NewUserRepository(pool, rawLogger)
+	customerRepo := customer.NewCustomerRepository(pool, rawLogger)
+	sellerRepo := sellerAdapter.NewSellerRepository(pool, rawLogger)
+	tokenRepo := token.NewTokenRepository(pool, rawLogger)
+	productRepo := productAdapter.NewProductRepository(pool, rawLogger)
+	apiTokenRepo := apiTokenAdapter.NewAPITokenRepository(pool, rawLogger)
+	imageRepo := productImageAdapter.NewProductImageRepository(pool, rawLogger)
+	categoryRepo := categoryAdapter.NewCategoryRepository(pool, rawLogger)
+	auditRepo := auditAdapter.NewAuditRepository(pool, rawLogger)
+	passwordHistoryRepo := passwordhistory.NewPasswordHistoryRepository(pool, rawLogger)
+	verificationRepo := verification.NewVerificationRepository(pool, rawLogger)
+
+	// Менеджеры
+	txManager := adapter.NewTxManager(pool)
+	bcryptManager := bcrypt.NewBcryptManager(rawLogger, cfg.Bcrypt.Cost, cfg.Bcrypt.RehashCost)
+	jwtManager := jwt.NewJWTManager(tokenRepo, rawLogger, cfg)
+	mail := mailer.NewLogMailer(rawLogger, cfg.Debug.LogMailTokens)
+	if cfg.Debug.LogMailTokens {
+		rawLogger.Warn("verification tokens are written to the log")
+	}
+
+	// Usecase
+	authUsecase := usecase.NewAuthUsecase(userRepo, customerRepo, sellerRepo, tokenRepo, auditRepo, passwordHistoryRepo, verificationRepo, mail, txManager, jwtManager, bcryptManager, rawLogger, cfg.Auth, cfg.DeletePolicy.Users)
+	prices := money.NewFormatter(cfg.Money)
+	views := usecaseProduct.NewViewCounter(productRepo, rawLogger, cfg.Product.ViewFlushInterval)
+	productUsecase := usecaseProduct.NewProductUsecase(productRepo, categoryRepo, rawLogger, validator.New(), cfg.Product, cfg.DeletePolicy.Products, prices, views)
+	apiTokenUsecase := usecaseAPIToken.NewAPITokenUsecase(apiTokenRepo, rawLogger)
+	imageUsecase := usecaseImages.NewImageUsecase(imageRepo, productRepo, rawLogger, validator.New(), cfg.Images)
+	categoryUsecase := usecaseCategory.NewCategoryUsecase(categoryRepo, rawLogger, validator.New(), cfg.DeletePolicy.Categories)
+	adminUsecase := usecaseAdmin.NewAdminUsecase(userRepo, tokenRepo, auditRepo, jwtManager, rawLogger, cfg.Admin)
+
+	// Handler
+	responder := response.New(rawLogger, response.Options{
+		JSONCase:     cfg.Server.JSONCase,
+		ErrorDetails: cfg.Debug.ErrorDetails,
+	})
+	if cfg.Debug.ErrorDetails {
+		rawLogger.Warn("error details are included in responses")
+	}
+	binder := response.NewBinder(cfg.Server.StrictJSON, cfg.Server.MaxOffset)
+	authHandler := auth.NewAuthHandler(authUsecase, responder, binder)
+	productHandler := product.NewProductHandler(productUsecase, imageUsecase, responder, binder, cfg.Product, prices)
+	sellerHandler := seller.NewSellerHandler(apiTokenUsecase, productUsecase, imageUsecase, responder, binder, rawLogger, cfg.Product)
+	imageHandler := images.NewImageHandler(imageUsecase, responder, binder)
+	categoryHandler := category.NewCategoryHandler(categoryUsecase, responder, binder)
+	adminHandler := admin.NewAdminHandler(adminUsecase, responder, binder)
+
+	// Gin router
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(gin.Logger())
+	r.Use(middleware.ClientInfoMiddleware())
+	if cfg.Server.HandlerTimeout > 0 {
+		r.Use(middleware.Timeout(cfg.Server.HandlerTimeout, seller.StreamingRoutes...))
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		r.Use(middleware.CORSMiddleware(cfg.CORS))
+	}
+
+	// Группа маршрутов
+	apiGroup := r.Group("/")
+	auth.RegisterAuthRoutes(apiGroup, authHandler, jwtManager, rawLogger)
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	})
+	r.GET("/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":            "alive",
+			"delete_policy":     cfg.DeletePolicy,
+			"password_rehashes": bcryptManager.RehashCount(),
+		})
+	})
+	product.RegisterProductRoutes(apiGroup, productHandler, jwtManager, apiTokenUsecase, cfg.Features, rawLogger)
+	seller.RegisterSellerRoutes(apiGroup, sellerHandler, jwtManager, rawLogger)
+	images.RegisterImageRoutes(apiGroup, imageHandler, jwtManager, apiTokenUsecase, rawLogger)
+	category.RegisterCategoryRoutes(apiGroup, categoryHandler, jwtManager, rawLogger)
+	admin.RegisterAdminRoutes(apiGroup, adminHandler, jwtManager, rawLogger)
+	if cfg.Debug.EnableTestRoute {
+		rawLogger.Warn("debug route POST /test is enabled")
+		// Only the field names are reported back so the route cannot be used
+		// to reflect arbitrary payloads.
+		r.POST("/test", func(c *gin.Context) {
+			var data map[string]interface{}
+			if err := c.ShouldBindJSON(&data); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid JSON"})
+				return
+			}
+			fields := make([]string, 0, len(data))
+			for field := range data {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			c.JSON(http.StatusOK, gin.H{"success": true, "fields": fields})
+		})
+	}
+
+	return r, views
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"marketplace/internal/handler/seller"
+	"marketplace/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TestNewRouter wires the application with no database behind it. Nothing
+// here touches the pool; the routes only need to be registered.
+func TestNewRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	r, views := newRouter(config.Config{}, nil, logger)
+	if views == nil {
+		t.Fatal("view counter is nil")
+	}
+
+	paths := make(map[string]bool)
+	for _, route := range r.Routes() {
+		paths[route.Path] = true
+	}
+	// The timeout middleware exempts streaming routes by their full path,
+	// so a renamed route would silently lose the exemption.
+	want := append([]string{"/healthz", "/status", "/auth/login", "/products/:productID", "/seller/products"}, seller.StreamingRoutes...)
+	for _, path := range want {
+		if !paths[path] {
+			t.Errorf("route %s is not registered", path)
+		}
+	}
+	if paths["/test"] {
+		t.Error("debug route is registered while disabled")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /status returned %d", w.Code)
+	}
+	var status map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status["password_rehashes"] != float64(0) {
+		t.Errorf("password_rehashes = %v, want 0", status["password_rehashes"])
+	}
+}
+
+func TestNewRouterDebugRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var cfg config.Config
+	cfg.Debug.EnableTestRoute = true
+	r, _ := newRouter(cfg, nil, logger)
+
+	for _, route := range r.Routes() {
+		if route.Method == http.MethodPost && route.Path == "/test" {
+			return
+		}
+	}
+	t.Error("debug route is not registered while enabled")
+}
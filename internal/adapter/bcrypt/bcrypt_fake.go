@@ -0,0 +1,38 @@
+package bcrypt
+
+import (
+	"marketplace/pkg/errors"
+	"strings"
+)
+
+const fakeHashPrefix = "fake$"
+
+var _ Hasher = FakeHasher{}
+
+// FakeHasher is a Hasher for tests. It "hashes" by prefixing the password, so
+// auth flows can be exercised without paying for bcrypt. Never use it outside
+// tests.
+type FakeHasher struct {
+	// Stale makes NeedsRehash report every hash as outdated.
+	Stale bool
+}
+
+func (FakeHasher) GenerateHashPassword(password string) (string, error) {
+	return fakeHashPrefix + password, nil
+}
+
+func (FakeHasher) CompareHashPassword(hash, password string) error {
+	if hash != fakeHashPrefix+password {
+		return errors.NewAppError("AUTH", "password mismatch", nil)
+	}
+
+	return nil
+}
+
+func (f FakeHasher) NeedsRehash(hash string) bool {
+	return f.Stale && strings.HasPrefix(hash, fakeHashPrefix)
+}
+
+func (f FakeHasher) Rehash(password string) (string, error) {
+	return f.GenerateHashPassword(password)
+}
@@ -3,4 +3,6 @@ package bcrypt
 type Hasher interface {
 	GenerateHashPassword(password string) (string, error)
 	CompareHashPassword(hash, password string) error
+	NeedsRehash(hash string) bool
+	Rehash(password string) (string, error)
 }
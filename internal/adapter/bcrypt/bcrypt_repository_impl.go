@@ -2,22 +2,35 @@ package bcrypt
 
 import (
 	"marketplace/pkg/errors"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var _ Hasher = (*BcryptManager)(nil)
+
 type BcryptManager struct {
-	logger *logrus.Logger
-	cost   int
+	logger     *logrus.Logger
+	cost       int
+	rehashCost int
+	rehashes   atomic.Int64
 }
 
-func NewBcryptManager(logger *logrus.Logger, cost int) *BcryptManager {
+// NewBcryptManager hashes new passwords with cost. Stored hashes with a cost
+// below rehashCost are reported by NeedsRehash, which lets operators raise
+// cost first and start upgrading existing hashes later. A zero rehashCost
+// disables rehashing.
+func NewBcryptManager(logger *logrus.Logger, cost, rehashCost int) *BcryptManager {
 	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
 		logger.Warnf("Invalid bcrypt cost %d, using default %d", cost, bcrypt.DefaultCost)
 		cost = bcrypt.DefaultCost
 	}
-	return &BcryptManager{logger: logger, cost: cost}
+	if rehashCost > cost {
+		logger.Warnf("Bcrypt rehash cost %d exceeds hashing cost %d, using %d", rehashCost, cost, cost)
+		rehashCost = cost
+	}
+	return &BcryptManager{logger: logger, cost: cost, rehashCost: rehashCost}
 }
 
 func (b *BcryptManager) GenerateHashPassword(password string) (string, error) {
@@ -52,3 +65,42 @@ func (b *BcryptManager) CompareHashPassword(hash, password string) error {
 
 	return nil
 }
+
+func (b *BcryptManager) NeedsRehash(hash string) bool {
+	if b.rehashCost <= 0 {
+		return false
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		b.logger.WithFields(logrus.Fields{
+			"method": "NeedsRehash",
+			"error":  err,
+		}).Warn("failed to read bcrypt cost")
+		return false
+	}
+
+	return cost < b.rehashCost
+}
+
+// Rehash hashes password with the current cost and counts the upgrade.
+func (b *BcryptManager) Rehash(password string) (string, error) {
+	hash, err := b.GenerateHashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	total := b.rehashes.Add(1)
+	b.logger.WithFields(logrus.Fields{
+		"method":       "Rehash",
+		"cost":         b.cost,
+		"rehash_total": total,
+	}).Info("password hash upgraded")
+
+	return hash, nil
+}
+
+// RehashCount returns how many password hashes were upgraded since start.
+func (b *BcryptManager) RehashCount() int64 {
+	return b.rehashes.Load()
+}
@@ -3,12 +3,23 @@ package jwt
 import (
 	"context"
 	"marketplace/internal/entity"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWTManager interface {
 	GenerateAccessToken(user *entity.User) (string, error)
+	// AccessTokenTTL is the lifetime of tokens from GenerateAccessToken.
+	AccessTokenTTL() time.Duration
+	// GenerateImpersonationToken issues an access token for user carrying the
+	// impersonator id in the "imp" claim. It is not backed by a session.
+	GenerateImpersonationToken(user *entity.User, impersonatorID string, ttl time.Duration) (string, time.Time, error)
 	ValidateAccessToken(tokenString string) error
 	GenerateRefreshToken(ctx context.Context, user *entity.User) (string, error)
+	// RefreshTokenTTL is the lifetime of tokens from GenerateRefreshToken.
+	RefreshTokenTTL() time.Duration
 	ValidateRefreshToken(ctx context.Context, tokenString string) error
 	Secret() string
+	ParserOptions() []jwt.ParserOption
 }
@@ -2,17 +2,32 @@ package jwt
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"marketplace/internal/adapter/postgres/token"
 	"marketplace/internal/entity"
+	"marketplace/pkg/clientinfo"
 	"marketplace/pkg/config"
 	appErrors "marketplace/pkg/errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+
+	// CodeTokenStore marks errors reading or writing refresh tokens in the
+	// database, as opposed to signing or validation errors.
+	CodeTokenStore = "JWT_DB"
+)
+
+var _ JWTManager = (*jwtManager)(nil)
+
 type jwtManager struct {
 	tokenRepo token.TokenRepository
 	logger    *logrus.Logger
@@ -31,21 +46,59 @@ func (j *jwtManager) GenerateAccessToken(user *entity.User) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":   user.ID,
 		"user_type": user.UserType,
-		"exp":       time.Now().Add(15 * time.Minute).Unix(),
+		"typ":       TokenTypeAccess,
+		"exp":       time.Now().Add(j.AccessTokenTTL()).Unix(),
 		"iat":       time.Now().Unix(),
+		"nbf":       time.Now().Unix(),
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return jwtToken.SignedString([]byte(j.cfg.JWT.SecretKey))
 }
 
+func (j *jwtManager) AccessTokenTTL() time.Duration {
+	return j.cfg.JWT.ExpiresIn
+}
+
+func (j *jwtManager) RefreshTokenTTL() time.Duration {
+	return j.cfg.JWT.RefreshExpiresIn
+}
+
+func (j *jwtManager) GenerateImpersonationToken(user *entity.User, impersonatorID string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"user_type": user.UserType,
+		"imp":       impersonatorID,
+		"typ":       TokenTypeAccess,
+		"exp":       expiresAt.Unix(),
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := jwtToken.SignedString([]byte(j.cfg.JWT.SecretKey))
+	if err != nil {
+		j.logger.WithFields(logrus.Fields{
+			"user_id": user.ID,
+			"imp":     impersonatorID,
+			"err":     err,
+		}).Error("failed to sign impersonation token")
+
+		return "", time.Time{}, appErrors.NewAppError("JWT_GENERATION", "failed to sign impersonation token", err)
+	}
+
+	return tokenString, expiresAt, nil
+}
+
 func (j *jwtManager) ValidateAccessToken(tokenString string) error {
 	jwtToken, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, appErrors.NewAppError("JWT_VALIDATION", "unexpected signing method", nil)
 		}
 		return []byte(j.cfg.JWT.SecretKey), nil
-	})
+	}, j.ParserOptions()...)
 
 	if err != nil {
 		j.logger.WithFields(logrus.Fields{
@@ -74,15 +127,23 @@ func (j *jwtManager) ValidateAccessToken(tokenString string) error {
 		return appErrors.NewAppError("JWT_VALIDATION", "user_type claim is missing", nil)
 	}
 
+	if err := j.checkTokenType(claims, TokenTypeAccess); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (j *jwtManager) GenerateRefreshToken(ctx context.Context, user *entity.User) (string, error) {
+	sessionID := uuid.NewString()
 	claims := jwt.MapClaims{
+		"jti":       sessionID,
 		"user_id":   user.ID,
 		"user_type": user.UserType,
-		"exp":       time.Now().Add(30 * 24 * time.Hour).Unix(),
+		"typ":       TokenTypeRefresh,
+		"exp":       time.Now().Add(j.RefreshTokenTTL()).Unix(),
 		"iat":       time.Now().Unix(),
+		"nbf":       time.Now().Unix(),
 	}
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -96,13 +157,17 @@ func (j *jwtManager) GenerateRefreshToken(ctx context.Context, user *entity.User
 		return "", appErrors.NewAppError("JWT_GENERATION", "failed to sign refresh token", err)
 	}
 
+	client := clientinfo.FromContext(ctx)
 	refreshToken := &entity.RefreshToken{
+		ID:        sessionID,
 		UserID:    user.ID,
 		Token:     tokenString,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour),
+		ExpiresAt: time.Now().Add(j.RefreshTokenTTL()),
 		IsRevoked: false,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		UserAgent: sql.NullString{String: client.UserAgent, Valid: client.UserAgent != ""},
+		IPAddress: sql.NullString{String: client.IP, Valid: client.IP != ""},
 	}
 
 	err = j.tokenRepo.UpsertRefreshToken(ctx, refreshToken)
@@ -112,19 +177,48 @@ func (j *jwtManager) GenerateRefreshToken(ctx context.Context, user *entity.User
 			"err":     err,
 		}).Error("failed to store refresh token in DB")
 
-		return "", appErrors.NewAppError("JWT_DB", "failed to store refresh token", err)
+		return "", appErrors.NewAppError(CodeTokenStore, "failed to store refresh token", err)
 	}
 
+	j.pruneSessions(ctx, user.ID)
+
 	return tokenString, nil
 }
 
+// pruneSessions enforces JWT.MaxSessions by revoking the user's oldest
+// sessions. A non-positive limit disables the cap. Failures are logged only,
+// since the new token has already been stored.
+func (j *jwtManager) pruneSessions(ctx context.Context, userID string) {
+	limit := j.cfg.JWT.MaxSessions
+	if limit <= 0 {
+		return
+	}
+
+	pruned, err := j.tokenRepo.RevokeOldestSessions(ctx, userID, limit)
+	if err != nil {
+		j.logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"err":     err,
+		}).Warn("failed to prune old sessions")
+		return
+	}
+
+	if pruned > 0 {
+		j.logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"pruned":       pruned,
+			"max_sessions": limit,
+		}).Info("pruned oldest sessions")
+	}
+}
+
 func (j *jwtManager) ValidateRefreshToken(ctx context.Context, tokenString string) error {
 	jwtToken, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, appErrors.NewAppError("JWT_VALIDATION", "unexpected signing method", nil)
 		}
 		return []byte(j.cfg.JWT.SecretKey), nil
-	})
+	}, j.ParserOptions()...)
 
 	if err != nil {
 		j.logger.WithFields(logrus.Fields{
@@ -155,31 +249,97 @@ func (j *jwtManager) ValidateRefreshToken(ctx context.Context, tokenString strin
 		return appErrors.NewAppError("JWT_VALIDATION", "user_type claim is missing or invalid", nil)
 	}
 
-	dbToken, err := j.tokenRepo.GetRefreshTokenByUserID(ctx, userID)
+	if err := j.checkTokenType(claims, TokenTypeRefresh); err != nil {
+		return err
+	}
+
+	dbToken, err := j.tokenRepo.GetByToken(ctx, tokenString)
 	if err != nil {
+		// A validly signed token without a row was deleted with its session;
+		// it is as invalid as a revoked one.
+		if errors.Is(err, appErrors.ErrNotFound) {
+			return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("refresh token not found for user %s", userID), err)
+		}
 		j.logger.WithFields(logrus.Fields{
 			"user_id": userID,
 			"err":     err,
 		}).Error("failed to fetch refresh token from DB")
 
-		return appErrors.NewAppError("JWT_DB", "failed to fetch refresh token", err)
+		return appErrors.NewAppError(CodeTokenStore, "failed to fetch refresh token", err)
 	}
 
-	if dbToken.Token != tokenString {
+	if dbToken.UserID != userID {
 		return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("refresh token mismatch for user %s", userID), nil)
 	}
 
+	// jti names the session row the token was issued for.
+	if sessionID, ok := claims["jti"].(string); !ok || sessionID != dbToken.ID {
+		return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("refresh token session mismatch for user %s", userID), nil)
+	}
+
 	if time.Now().After(dbToken.ExpiresAt) {
 		return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("refresh token expired for user %s", userID), nil)
 	}
 
 	if dbToken.IsRevoked {
+		j.revokeOnReuse(ctx, userID, dbToken.ID)
 		return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("refresh token revoked for user %s", userID), nil)
 	}
 
+	if err := j.tokenRepo.TouchSession(ctx, dbToken.ID, time.Now()); err != nil {
+		j.logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"session_id": dbToken.ID,
+			"err":        err,
+		}).Warn("failed to update session last use")
+	}
+
 	return nil
 }
 
+// checkTokenType rejects a token whose typ claim is not want, so a refresh
+// token cannot be presented as an access token and vice versa.
+func (j *jwtManager) checkTokenType(claims jwt.MapClaims, want string) error {
+	typ, ok := claims["typ"].(string)
+	if !ok && !j.cfg.JWT.RequireTokenType {
+		return nil
+	}
+	if typ != want {
+		return appErrors.NewAppError("JWT_VALIDATION", fmt.Sprintf("expected %s token", want), nil)
+	}
+
+	return nil
+}
+
+// revokeOnReuse handles a rotated-out refresh token being presented again.
+// It most likely leaked, so every session of the user is revoked.
+func (j *jwtManager) revokeOnReuse(ctx context.Context, userID, sessionID string) {
+	revoked, err := j.tokenRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		j.logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"session_id": sessionID,
+			"err":        err,
+		}).Error("failed to revoke sessions after refresh token reuse")
+		return
+	}
+
+	j.logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"session_id": sessionID,
+		"revoked":    revoked,
+	}).Warn("revoked refresh token reused, all sessions revoked")
+}
+
 func (j *jwtManager) Secret() string {
 	return j.cfg.JWT.SecretKey
 }
+
+// ParserOptions makes parsing reject tokens whose iat or nbf lies in the
+// future, allowing the configured clock-skew leeway.
+func (j *jwtManager) ParserOptions() []jwt.ParserOption {
+	return []jwt.ParserOption{
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(j.cfg.JWT.Leeway),
+	}
+}
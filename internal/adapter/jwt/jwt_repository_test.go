@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"marketplace/pkg/config"
+	appErrors "marketplace/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const testSecret = "test-secret"
+
+func newTestManager() *jwtManager {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return NewJWTManager(nil, logger, config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:        testSecret,
+			ExpiresIn:        time.Minute,
+			RefreshExpiresIn: time.Hour,
+			Leeway:           5 * time.Second,
+			RequireTokenType: true,
+		},
+	})
+}
+
+// sign returns an access token with the given claims on top of valid ones.
+func sign(t *testing.T, overrides jwt.MapClaims) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id":   "user-1",
+		"user_type": "customer",
+		"typ":       TokenTypeAccess,
+		"exp":       now.Add(time.Minute).Unix(),
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
+
+func TestValidateAccessTokenRejectsFutureTokens(t *testing.T) {
+	j := newTestManager()
+	future := time.Now().Add(time.Hour).Unix()
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"iat": {"iat": future},
+		"nbf": {"nbf": future},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assertCode(t, j.ValidateAccessToken(sign(t, claims)), "JWT_VALIDATION")
+		})
+	}
+}
+
+func TestValidateAccessTokenAllowsLeeway(t *testing.T) {
+	j := newTestManager()
+	skewed := time.Now().Add(2 * time.Second).Unix()
+
+	if err := j.ValidateAccessToken(sign(t, jwt.MapClaims{"iat": skewed, "nbf": skewed})); err != nil {
+		t.Errorf("token within leeway: %v", err)
+	}
+}
+
+func TestValidateRejectsWrongTokenType(t *testing.T) {
+	j := newTestManager()
+
+	assertCode(t, j.ValidateAccessToken(sign(t, jwt.MapClaims{"typ": TokenTypeRefresh})), "JWT_VALIDATION")
+	assertCode(t, j.ValidateAccessToken(sign(t, jwt.MapClaims{"typ": nil})), "JWT_VALIDATION")
+
+	// The type is checked before the token store, which this manager lacks.
+	assertCode(t, j.ValidateRefreshToken(context.Background(), sign(t, nil)), "JWT_VALIDATION")
+}
+
+func TestValidateAccessTokenAllowsMissingTypeWhenNotRequired(t *testing.T) {
+	j := newTestManager()
+	j.cfg.JWT.RequireTokenType = false
+
+	if err := j.ValidateAccessToken(sign(t, jwt.MapClaims{"typ": nil})); err != nil {
+		t.Errorf("token without typ: %v", err)
+	}
+	assertCode(t, j.ValidateAccessToken(sign(t, jwt.MapClaims{"typ": TokenTypeRefresh})), "JWT_VALIDATION")
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Mailer = (*LogMailer)(nil)
+
+// LogMailer writes emails to the log instead of sending them. It stands in
+// until a real provider is wired up. Tokens are credentials, so they are
+// only logged when logTokens is set for local development.
+type LogMailer struct {
+	logger    *logrus.Logger
+	logTokens bool
+}
+
+func NewLogMailer(logger *logrus.Logger, logTokens bool) *LogMailer {
+	return &LogMailer{logger: logger, logTokens: logTokens}
+}
+
+func (m *LogMailer) SendVerification(ctx context.Context, email, token string) error {
+	fields := logrus.Fields{"email": email}
+	if m.logTokens {
+		fields["token"] = token
+	}
+	m.logger.WithFields(fields).Info("verification email (not sent)")
+	return nil
+}
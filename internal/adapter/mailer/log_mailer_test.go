@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLogMailerOmitsTokensByDefault(t *testing.T) {
+	for _, logTokens := range []bool{false, true} {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		hook := test.NewLocal(logger)
+
+		if err := NewLogMailer(logger, logTokens).SendVerification(context.Background(), "a@example.com", "secret"); err != nil {
+			t.Fatalf("SendVerification: %v", err)
+		}
+		entry := hook.LastEntry()
+		if entry == nil {
+			t.Fatal("nothing was logged")
+		}
+		if entry.Data["email"] != "a@example.com" {
+			t.Errorf("email = %v, want a@example.com", entry.Data["email"])
+		}
+		if _, logged := entry.Data["token"]; logged != logTokens {
+			t.Errorf("logTokens=%v: token logged = %v", logTokens, logged)
+		}
+	}
+}
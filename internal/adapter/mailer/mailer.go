@@ -0,0 +1,9 @@
+package mailer
+
+import "context"
+
+// Mailer delivers transactional emails.
+type Mailer interface {
+	// SendVerification sends the one-time token that confirms email.
+	SendVerification(ctx context.Context, email, token string) error
+}
@@ -0,0 +1,12 @@
+package apitoken
+
+import (
+	"context"
+	"marketplace/internal/entity"
+)
+
+type APITokenRepository interface {
+	Upsert(ctx context.Context, token *entity.SellerAPIToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*entity.SellerAPIToken, error)
+	Revoke(ctx context.Context, sellerID string) error
+}
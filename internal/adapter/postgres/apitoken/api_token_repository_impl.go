@@ -0,0 +1,169 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/entity"
+	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+var _ APITokenRepository = (*apiTokenRepository)(nil)
+
+type apiTokenRepository struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewAPITokenRepository(pool *pgxpool.Pool, logger *logrus.Logger) *apiTokenRepository {
+	return &apiTokenRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *apiTokenRepository) Upsert(ctx context.Context, token *entity.SellerAPIToken) error {
+	query, args, err := psql.
+		Insert("seller_api_tokens").
+		Columns(
+			"seller_id",
+			"token_hash",
+			"is_revoked",
+			"created_at",
+			"updated_at",
+		).
+		Values(
+			token.SellerID,
+			token.TokenHash,
+			token.IsRevoked,
+			token.CreatedAt,
+			token.UpdatedAt,
+		).
+		Suffix(`
+			ON CONFLICT (seller_id) DO UPDATE
+			SET token_hash = EXCLUDED.token_hash,
+				is_revoked = EXCLUDED.is_revoked,
+				created_at = EXCLUDED.created_at,
+				updated_at = EXCLUDED.updated_at
+		`).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":    "Upsert",
+			"seller_id": token.SellerID,
+			"error":     err,
+		}).Error("failed to build SQL upsert query")
+		return appErrors.NewAppError("SQL_BUILD_ERROR", "could not build api token upsert query", err)
+	}
+
+	if _, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":    "Upsert",
+			"seller_id": token.SellerID,
+			"error":     err,
+		}).Error("failed to execute upsert query")
+		return appErrors.NewAppError("EXEC_ERROR", "could not store api token", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"method":    "Upsert",
+		"seller_id": token.SellerID,
+	}).Info("api token successfully upserted")
+
+	return nil
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.SellerAPIToken, error) {
+	query, args, err := psql.
+		Select(
+			"seller_id",
+			"token_hash",
+			"is_revoked",
+			"created_at",
+			"updated_at",
+		).
+		From("seller_api_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method": "GetByHash",
+			"error":  err,
+		}).Error("failed to build SQL query")
+		return nil, appErrors.NewAppError("SQL_BUILD_ERROR", "could not build api token query", err)
+	}
+
+	var t entity.SellerAPIToken
+	if err := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&t.SellerID,
+		&t.TokenHash,
+		&t.IsRevoked,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, appErrors.NewAppError("NOT_FOUND", "api token not found", appErrors.ErrNotFound)
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method": "GetByHash",
+			"error":  err,
+		}).Error("failed to scan row")
+		return nil, appErrors.NewAppError("EXEC_ERROR", "could not fetch api token", err)
+	}
+
+	return &t, nil
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, sellerID string) error {
+	query, args, err := psql.
+		Update("seller_api_tokens").
+		Set("is_revoked", true).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"seller_id": sellerID}).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":    "Revoke",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Error("failed to build SQL update query")
+		return appErrors.NewAppError("SQL_BUILD_ERROR", "could not build api token revoke query", err)
+	}
+
+	res, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":    "Revoke",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Error("failed to execute update query")
+		return appErrors.NewAppError("EXEC_ERROR", "could not revoke api token", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appErrors.NewAppError("NOT_FOUND", "api token not found", appErrors.ErrNotFound)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"method":    "Revoke",
+		"seller_id": sellerID,
+	}).Info("api token revoked")
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"context"
+	"marketplace/internal/entity"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, event *entity.AuditEvent) error
+	// List returns matching events, newest first.
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]entity.AuditEvent, error)
+}
+
+// ListFilter narrows List. Empty fields add no filter.
+type ListFilter struct {
+	UserID string
+	Event  string
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"marketplace/internal/entity"
+	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+var auditColumns = []string{
+	"id",
+	"user_id",
+	"event",
+	"ip_address",
+	"user_agent",
+	"created_at",
+}
+
+var _ AuditRepository = (*auditRepository)(nil)
+
+type auditRepository struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewAuditRepository(pool *pgxpool.Pool, logger *logrus.Logger) *auditRepository {
+	return &auditRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *auditRepository) Record(ctx context.Context, event *entity.AuditEvent) error {
+	query, args, err := psql.
+		Insert("audit_log").
+		Columns(auditColumns...).
+		Values(
+			event.ID,
+			event.UserID,
+			event.Event,
+			event.IPAddress,
+			event.UserAgent,
+			event.CreatedAt,
+		).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method": "Record",
+			"event":  event.Event,
+			"error":  err,
+		}).Error("failed to build SQL insert query")
+		return appErrors.NewAppError("SQL_BUILD_ERROR", "could not build audit insert query", err)
+	}
+
+	if _, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Record",
+			"user_id": event.UserID,
+			"event":   event.Event,
+			"error":   err,
+		}).Error("failed to execute insert query")
+		return appErrors.NewAppError("EXEC_ERROR", "could not store audit event", err)
+	}
+
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]entity.AuditEvent, error) {
+	builder := psql.
+		Select(auditColumns...).
+		From("audit_log").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	if filter.UserID != "" {
+		builder = builder.Where(sq.Eq{"user_id": filter.UserID})
+	}
+	if filter.Event != "" {
+		builder = builder.Where(sq.Eq{"event": filter.Event})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method": "List",
+			"error":  err,
+		}).Error("failed to build SQL select query")
+		return nil, appErrors.NewAppError("SQL_BUILD_ERROR", "could not build audit select query", err)
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method": "List",
+			"error":  err,
+		}).Error("failed to execute select query")
+		return nil, appErrors.NewAppError("EXEC_ERROR", "could not list audit events", err)
+	}
+	defer rows.Close()
+
+	events := []entity.AuditEvent{}
+	for rows.Next() {
+		var e entity.AuditEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.UserID,
+			&e.Event,
+			&e.IPAddress,
+			&e.UserAgent,
+			&e.CreatedAt,
+		); err != nil {
+			if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
+			r.logger.WithFields(logrus.Fields{
+				"method": "List",
+				"error":  err,
+			}).Error("failed to scan audit row")
+			return nil, appErrors.NewAppError("SCAN_ERROR", "could not scan audit event", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method": "List",
+			"error":  err,
+		}).Error("error after scanning audit rows")
+		return nil, appErrors.NewAppError("SCAN_ERROR", "could not read audit events", err)
+	}
+
+	return events, nil
+}
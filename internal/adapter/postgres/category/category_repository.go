@@ -5,10 +5,20 @@ import (
 	"marketplace/internal/entity"
 )
 
+// CategoryRepository never returns soft deleted categories.
 type CategoryRepository interface {
 	Create(ctx context.Context, category *entity.Category) error
 	GetByID(ctx context.Context, id string) (*entity.Category, error)
+	// Exists reports whether a row with field = value exists. field is a
+	// column name and must never come from user input.
+	Exists(ctx context.Context, field, value string) (bool, error)
 	Update(ctx context.Context, category *entity.Category) error
 	Delete(ctx context.Context, id string) error
+	// SoftDelete marks the category deleted and keeps the row.
+	SoftDelete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]entity.Category, error)
+	// ListChildren returns the direct subcategories of parentID.
+	ListChildren(ctx context.Context, parentID string) ([]entity.Category, error)
+	// ListAll returns every category ordered by name.
+	ListAll(ctx context.Context) ([]entity.Category, error)
 }
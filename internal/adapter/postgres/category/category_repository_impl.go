@@ -4,6 +4,8 @@ import (
 	"context"
 	"marketplace/internal/entity"
 	"marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
@@ -17,7 +19,6 @@ const (
 	errCodeBuildQuery = "BUILD_QUERY"
 	errCodeExecQuery  = "EXEC_QUERY"
 	errCodeScanErr    = "SCAN_ERR"
-	errCodeAcquire    = "ACQUIRE_CONN"
 	errCodeBeginTx    = "BEGIN_TX"
 	errCodeCommitTx   = "COMMIT_TX"
 	errCodeRollbackTx = "ROLLBACK_TX"
@@ -26,12 +27,18 @@ const (
 var categoryColums = []string{
 	"id",
 	"name",
+	"parent_id",
 	"created_at",
 	"updated_at",
 }
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+// notDeleted excludes soft deleted categories.
+var notDeleted = sq.Eq{"deleted_at": nil}
+
+var _ CategoryRepository = (*categoryRepository)(nil)
+
 type categoryRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -52,6 +59,7 @@ func (s *categoryRepository) Create(ctx context.Context, category *entity.Catego
 			Values(
 				category.ID,
 				category.Name,
+				category.ParentID,
 				category.CreatedAt,
 				category.UpdatedAt,
 			).
@@ -62,6 +70,9 @@ func (s *categoryRepository) Create(ctx context.Context, category *entity.Catego
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute create query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -81,8 +92,10 @@ func (s *categoryRepository) Update(ctx context.Context, category *entity.Catego
 		query, args, err := psql.
 			Update(tableCategories).
 			Set("name", category.Name).
+			Set("parent_id", category.ParentID).
 			Set("updated_at", category.UpdatedAt).
 			Where(sq.Eq{"id": category.ID}).
+			Where(notDeleted).
 			ToSql()
 		if err != nil {
 			return errors.NewAppError(errCodeBuildQuery, "failed to build query", err)
@@ -90,6 +103,9 @@ func (s *categoryRepository) Update(ctx context.Context, category *entity.Catego
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute update query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -117,6 +133,9 @@ func (s *categoryRepository) Delete(ctx context.Context, id string) error {
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -131,56 +150,57 @@ func (s *categoryRepository) Delete(ctx context.Context, id string) error {
 	})
 }
 
-func (s *categoryRepository) List(ctx context.Context, limit int, offset int) ([]entity.Category, error) {
-	builder := psql.Select(categoryColums...).From(tableCategories).Limit(uint64(limit)).Offset(uint64(offset))
+func (s *categoryRepository) SoftDelete(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		now := time.Now()
+		query, args, err := psql.
+			Update(tableCategories).
+			Set("deleted_at", now).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": id}).
+			Where(notDeleted).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
 
-	query, args, err := builder.ToSql()
-	if err != nil {
-		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
-	}
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute soft delete query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			s.logger.WithFields(logrus.Fields{
+				"operation": "soft_delete",
+				"id":        id,
+			}).Warn("No rows affected during soft delete")
+		}
+		return nil
+	})
+}
 
-	rows, err := s.pool.Query(ctx, query, args...)
-	if err != nil {
-		s.logger.WithFields(logrus.Fields{
-			"operation": "list",
-			"limit":     limit,
-			"offset":    offset,
-			"query":     query,
-			"args":      args,
-			"error":     err,
-		}).Error("Failed to execute list query")
-		return nil, errors.NewAppError(errCodeExecQuery, "failed execute list query", err)
-	}
+func (s *categoryRepository) List(ctx context.Context, limit int, offset int) ([]entity.Category, error) {
+	builder := psql.Select(categoryColums...).From(tableCategories).Where(notDeleted).Limit(uint64(limit)).Offset(uint64(offset))
 
-	defer rows.Close()
+	return s.queryCategories(ctx, "list", builder)
+}
 
-	var categories []entity.Category
-	for rows.Next() {
-		var c entity.Category
-		if err := rows.Scan(
-			&c.ID,
-			&c.Name,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-		); err != nil {
-			s.logger.WithFields(logrus.Fields{
-				"operation": "list",
-				"error":     err,
-			}).Error("Failed to scan query row")
-			return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
-		}
-		categories = append(categories, c)
-	}
+func (s *categoryRepository) ListAll(ctx context.Context) ([]entity.Category, error) {
+	builder := psql.Select(categoryColums...).From(tableCategories).Where(notDeleted).OrderBy("name ASC")
 
-	if err := rows.Err(); err != nil {
-		s.logger.WithFields(logrus.Fields{
-			"operation": "list",
-			"error":     err,
-		}).Error("Error after scanning rows")
-		return nil, errors.NewAppError(errCodeScanErr, "error after scanning rows", err)
-	}
+	return s.queryCategories(ctx, "list_all", builder)
+}
 
-	return categories, nil
+func (s *categoryRepository) ListChildren(ctx context.Context, parentID string) ([]entity.Category, error) {
+	builder := psql.Select(categoryColums...).
+		From(tableCategories).
+		Where(sq.Eq{"parent_id": parentID}).
+		Where(notDeleted).
+		OrderBy("name ASC")
+
+	return s.queryCategories(ctx, "list_children", builder)
 }
 
 func (s *categoryRepository) GetByID(ctx context.Context, id string) (*entity.Category, error) {
@@ -188,6 +208,7 @@ func (s *categoryRepository) GetByID(ctx context.Context, id string) (*entity.Ca
 		Select(categoryColums...).
 		From(tableCategories).
 		Where(sq.Eq{"id": id}).
+		Where(notDeleted).
 		Limit(1).
 		ToSql()
 	if err != nil {
@@ -195,15 +216,13 @@ func (s *categoryRepository) GetByID(ctx context.Context, id string) (*entity.Ca
 	}
 
 	var c entity.Category
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
-		&c.ID,
-		&c.Name,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	err = scanCategory(adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...), &c)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, errors.NewAppError("NOT_FOUND", "category not found", errors.ErrNotFound)
 		}
 		s.logger.WithFields(logrus.Fields{
 			"operation": "get_by",
@@ -218,28 +237,124 @@ func (s *categoryRepository) GetByID(ctx context.Context, id string) (*entity.Ca
 	return &c, nil
 }
 
-func (s *categoryRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
-	conn, err := s.pool.Acquire(ctx)
+func (s *categoryRepository) Exists(ctx context.Context, field, value string) (bool, error) {
+	query, args, err := psql.
+		Select("1").
+		From(tableCategories).
+		Where(sq.Eq{field: value}).
+		Where(notDeleted).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
 	if err != nil {
-		return errors.NewAppError(errCodeAcquire, "failed to acquire connection", err)
+		return false, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
 	}
-	defer conn.Release()
 
-	tx, err := conn.Begin(ctx)
+	var exists bool
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return false, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": "exists",
+			"field":     field,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to scan query row")
+		return false, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+	}
+
+	return exists, nil
+}
+
+func (s *categoryRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := adapter.QuerierFrom(ctx, s.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeBeginTx, "failed to begin transaction", err)
 	}
 
 	if err = fn(tx); err != nil {
-		if rbErr := tx.Rollback(ctx); rbErr != nil {
+		// A canceled context also fails the rollback; the original error
+		// already says why, and pgx discards the connection.
+		if rbErr := tx.Rollback(ctx); rbErr != nil && errors.FromContext(rbErr) == nil {
 			return errors.NewAppError(errCodeRollbackTx, "failed to rollback transaction", rbErr)
 		}
 		return err
 	}
 
 	if cmErr := tx.Commit(ctx); cmErr != nil {
+		if ctxErr := errors.FromContext(cmErr); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeCommitTx, "failed to commit transaction", cmErr)
 	}
 
 	return nil
 }
+
+func (s *categoryRepository) queryCategories(ctx context.Context, operation string, builder sq.SelectBuilder) ([]entity.Category, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, s.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": operation,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to execute query")
+		return nil, errors.NewAppError(errCodeExecQuery, "failed execute "+operation+" query", err)
+	}
+
+	defer rows.Close()
+
+	categories := []entity.Category{}
+	for rows.Next() {
+		var c entity.Category
+		if err := scanCategory(rows, &c); err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
+			s.logger.WithFields(logrus.Fields{
+				"operation": operation,
+				"error":     err,
+			}).Error("Failed to scan query row")
+			return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": operation,
+			"error":     err,
+		}).Error("Error after scanning rows")
+		return nil, errors.NewAppError(errCodeScanErr, "error after scanning rows", err)
+	}
+
+	return categories, nil
+}
+
+// scanCategory scans a row selected with categoryColums.
+func scanCategory(row pgx.Row, c *entity.Category) error {
+	return row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.ParentID,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
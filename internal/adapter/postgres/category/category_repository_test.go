@@ -0,0 +1,41 @@
+package category
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestRepository(t *testing.T) *categoryRepository {
+	t.Helper()
+	pool := pgtest.New(t)
+	pgtest.Category(t, pool, "c1")
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewCategoryRepository(pool, logger)
+}
+
+func TestExists(t *testing.T) {
+	repo := newTestRepository(t)
+
+	for value, want := range map[string]bool{"c1": true, "c2": false} {
+		exists, err := repo.Exists(context.Background(), "id", value)
+		if err != nil {
+			t.Fatalf("Exists(%s): %v", value, err)
+		}
+		if exists != want {
+			t.Errorf("Exists(%s) = %v, want %v", value, exists, want)
+		}
+	}
+}
+
+func TestGetByIDUnknownIsNotFound(t *testing.T) {
+	repo := newTestRepository(t)
+
+	_, err := repo.GetByID(context.Background(), "missing")
+	pgtest.AssertNotFound(t, err)
+}
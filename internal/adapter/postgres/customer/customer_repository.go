@@ -7,6 +7,12 @@ import (
 
 type CustomerRepository interface {
 	UpdateProfile(ctx context.Context, profile *entity.CustomerProfile) error
+	// UpdateAddress writes only the address columns of profile.
+	UpdateAddress(ctx context.Context, profile *entity.CustomerProfile) error
+	GetByID(ctx context.Context, userID string) (*entity.CustomerProfile, error)
 	GetByUsername(ctx context.Context, username string) (*entity.CustomerProfile, error)
 	GetByEmail(ctx context.Context, email string) (*entity.CustomerProfile, error)
+	// PhoneTaken reports whether an active customer other than userID has
+	// the phone.
+	PhoneTaken(ctx context.Context, phone, userID string) (bool, error)
 }
@@ -2,17 +2,26 @@ package customer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"marketplace/internal/entity"
 	appError "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+// pgUniqueViolation is the SQLSTATE of a unique index violation.
+const pgUniqueViolation = "23505"
+
+var _ CustomerRepository = (*customerRepository)(nil)
+
 type customerRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -22,9 +31,57 @@ func NewCustomerRepository(pool *pgxpool.Pool, logger *logrus.Logger) *customerR
 	return &customerRepository{pool: pool, logger: logger}
 }
 
-func (r *customerRepository) UpdateProfile(ctx context.Context, profile *entity.CustomerProfile) (err error) {
-	tx, err := r.pool.Begin(ctx)
+func (r *customerRepository) UpdateProfile(ctx context.Context, profile *entity.CustomerProfile) error {
+	set := map[string]interface{}{
+		"first_name": profile.FirstName,
+		"last_name":  profile.LastName,
+		"phone":      profile.Phone,
+		"phone_key":  profile.PhoneKey,
+		"date_birth": profile.DateBirth,
+		"address":    profile.Address,
+	}
+	// The structured address is only written when it is part of the update.
+	if profile.AddressLine1.Valid {
+		addAddress(set, profile)
+	}
+
+	if err := r.updateCustomer(ctx, profile.ID, profile.UpdatedAt, set); err != nil {
+		return err
+	}
+
+	r.logger.WithField("user_id", profile.ID).Info("customer profile updated successfully")
+	return nil
+}
+
+func (r *customerRepository) UpdateAddress(ctx context.Context, profile *entity.CustomerProfile) error {
+	set := map[string]interface{}{
+		"address": profile.Address,
+	}
+	addAddress(set, profile)
+
+	if err := r.updateCustomer(ctx, profile.ID, profile.UpdatedAt, set); err != nil {
+		return err
+	}
+
+	r.logger.WithField("user_id", profile.ID).Info("customer address updated successfully")
+	return nil
+}
+
+func addAddress(set map[string]interface{}, profile *entity.CustomerProfile) {
+	set["address_line1"] = profile.AddressLine1
+	set["address_city"] = profile.AddressCity
+	set["address_postal_code"] = profile.AddressPostalCode
+	set["address_country"] = profile.AddressCountry
+}
+
+// updateCustomer applies set to the customer row and bumps the user's
+// updated_at in one transaction.
+func (r *customerRepository) updateCustomer(ctx context.Context, userID string, updatedAt time.Time, set map[string]interface{}) (err error) {
+	tx, err := adapter.QuerierFrom(ctx, r.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to begin transaction")
 		return appError.NewAppError("TX_BEGIN_FAIL", "could not begin transaction", err)
 	}
@@ -34,18 +91,18 @@ func (r *customerRepository) UpdateProfile(ctx context.Context, profile *entity.
 				r.logger.WithError(rbErr).Error("failed to rollback tx")
 			}
 		} else if cmErr := tx.Commit(ctx); cmErr != nil {
+			if ctxErr := appError.FromContext(cmErr); ctxErr != nil {
+				err = ctxErr
+				return
+			}
 			err = appError.NewAppError("TX_COMMIT_FAIL", "could not commit transaction", cmErr)
 		}
 	}()
 
 	cQuery, cArgs, err := psql.
 		Update("customers").
-		Set("first_name", profile.FirstName).
-		Set("last_name", profile.LastName).
-		Set("phone", profile.Phone).
-		Set("date_birth", profile.DateBirth).
-		Set("address", profile.Address).
-		Where(sq.Eq{"user_id": profile.ID}).
+		SetMap(set).
+		Where(sq.Eq{"user_id": userID}).
 		ToSql()
 	if err != nil {
 		r.logger.WithError(err).Error("failed to build customer update query")
@@ -53,13 +110,21 @@ func (r *customerRepository) UpdateProfile(ctx context.Context, profile *entity.
 	}
 
 	if _, err = tx.Exec(ctx, cQuery, cArgs...); err != nil { // tx!
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		// Only idx_customers_phone_key is unique among the updated columns;
+		// it catches a phone claimed between the check and this write.
+		if isUniqueViolation(err) {
+			return appError.NewAppError("DUPLICATE", "phone already exists", err)
+		}
 		return appError.NewAppError("EXEC_ERROR", "could not execute customer update", err)
 	}
 
 	uQuery, uArgs, err := psql.
 		Update("users").
-		Set("updated_at", profile.UpdatedAt).
-		Where(sq.Eq{"id": profile.ID}).
+		Set("updated_at", updatedAt).
+		Where(sq.Eq{"id": userID}).
 		ToSql()
 	if err != nil {
 		r.logger.WithError(err).Error("failed to build user update query")
@@ -67,13 +132,19 @@ func (r *customerRepository) UpdateProfile(ctx context.Context, profile *entity.
 	}
 
 	if _, err = tx.Exec(ctx, uQuery, uArgs...); err != nil { // tx!
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return appError.NewAppError("EXEC_ERROR", "could not execute user update", err)
 	}
 
-	r.logger.WithField("user_id", profile.ID).Info("customer profile updated successfully")
 	return nil
 }
 
+func (r *customerRepository) GetByID(ctx context.Context, userID string) (*entity.CustomerProfile, error) {
+	return r.getByField(ctx, "id", userID)
+}
+
 func (r *customerRepository) GetByUsername(ctx context.Context, username string) (*entity.CustomerProfile, error) {
 	return r.getByField(ctx, "username", username)
 }
@@ -82,16 +153,44 @@ func (r *customerRepository) GetByEmail(ctx context.Context, email string) (*ent
 	return r.getByField(ctx, "email", email)
 }
 
+func (r *customerRepository) PhoneTaken(ctx context.Context, phone, userID string) (bool, error) {
+	query, args, err := psql.
+		Select("1").
+		From("customers c").
+		Join("users u ON u.id = c.user_id").
+		Where(sq.Eq{"c.phone": phone, "u.deleted_at": nil}).
+		Where(sq.NotEq{"c.user_id": userID}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		r.logger.WithError(err).Error("failed to build phone taken query")
+		return false, appError.NewAppError("SQL_BUILD_ERROR", "could not build phone taken query", err)
+	}
+
+	var taken bool
+	if err := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&taken); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return false, ctxErr
+		}
+		r.logger.WithError(err).Error("failed to execute phone taken query")
+		return false, appError.NewAppError("EXEC_ERROR", "could not execute phone taken query", err)
+	}
+
+	return taken, nil
+}
+
 func (r *customerRepository) getByField(ctx context.Context, field, value string) (*entity.CustomerProfile, error) {
 	query, args, err := psql.
 		Select(
 			"u.id", "u.username", "u.password_hash", "u.email",
 			"u.updated_at", "u.created_at",
 			"c.first_name", "c.last_name", "c.phone", "c.date_birth", "c.address",
+			"c.address_line1", "c.address_city", "c.address_postal_code", "c.address_country",
 		).
 		From("users u").
 		Join("customers c ON u.id = c.user_id").
-		Where(sq.Eq{fmt.Sprintf("u.%s", field): value}).
+		Where(sq.Eq{fmt.Sprintf("u.%s", field): value, "u.deleted_at": nil}).
 		ToSql()
 	if err != nil {
 		r.logger.WithError(err).Error("failed to build getByField query")
@@ -99,12 +198,16 @@ func (r *customerRepository) getByField(ctx context.Context, field, value string
 	}
 
 	var c entity.CustomerProfile
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...)
 	if err := row.Scan(
 		&c.ID, &c.Username, &c.PasswordHash, &c.Email,
 		&c.UpdatedAt, &c.CreatedAt,
 		&c.FirstName, &c.LastName, &c.Phone, &c.DateBirth, &c.Address,
+		&c.AddressLine1, &c.AddressCity, &c.AddressPostalCode, &c.AddressCountry,
 	); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		r.logger.WithError(err).Warn("customer not found")
 		return nil, appError.NewAppError("NOT_FOUND", "customer not found", appError.ErrNotFound)
 	}
@@ -112,3 +215,9 @@ func (r *customerRepository) getByField(ctx context.Context, field, value string
 	r.logger.WithField("user_id", c.ID).Info("customer profile retrieved")
 	return &c, nil
 }
+
+// isUniqueViolation reports whether err is a unique index violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,59 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"marketplace/internal/entity"
+	apperrors "marketplace/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetUnknownIsNotFound(t *testing.T) {
+	pool := pgtest.New(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := NewCustomerRepository(pool, logger)
+
+	pgtest.AssertLookupsNotFound(t, map[string]pgtest.Lookup{
+		"GetByID":       pgtest.Missing(repo.GetByID, "missing"),
+		"GetByEmail":    pgtest.Missing(repo.GetByEmail, "nobody@example.com"),
+		"GetByUsername": pgtest.Missing(repo.GetByUsername, "nobody"),
+	})
+}
+
+// TestUpdateProfileRejectsTakenPhoneKey covers the race the usecase check
+// cannot: the second writer of a phone key is turned away by the index.
+func TestUpdateProfileRejectsTakenPhoneKey(t *testing.T) {
+	pool := pgtest.New(t)
+	pgtest.Customer(t, pool, "customer-1")
+	pgtest.Customer(t, pool, "customer-2")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := NewCustomerRepository(pool, logger)
+	ctx := context.Background()
+
+	phone := sql.NullString{String: "+15550100", Valid: true}
+	profile := func(id string, key sql.NullString) *entity.CustomerProfile {
+		return &entity.CustomerProfile{User: entity.User{ID: id, UpdatedAt: time.Now()}, Phone: phone, PhoneKey: key}
+	}
+
+	if err := repo.UpdateProfile(ctx, profile("customer-1", phone)); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	err := repo.UpdateProfile(ctx, profile("customer-2", phone))
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code() != "DUPLICATE" {
+		t.Errorf("error = %v, want DUPLICATE", err)
+	}
+
+	// Phones written without a key are not checked.
+	if err := repo.UpdateProfile(ctx, profile("customer-2", sql.NullString{})); err != nil {
+		t.Errorf("UpdateProfile without key: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package passwordhistory
+
+import "context"
+
+// PasswordHistoryRepository keeps the hashes of passwords a user has
+// replaced, so they cannot be reused.
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, userID, passwordHash string) error
+	// ListRecent returns up to limit hashes, newest first.
+	ListRecent(ctx context.Context, userID string, limit int) ([]string, error)
+	// Prune deletes all but the newest keep hashes of the user.
+	Prune(ctx context.Context, userID string, keep int) (int64, error)
+}
@@ -0,0 +1,154 @@
+package passwordhistory
+
+import (
+	"context"
+	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+var _ PasswordHistoryRepository = (*passwordHistoryRepository)(nil)
+
+type passwordHistoryRepository struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewPasswordHistoryRepository(pool *pgxpool.Pool, logger *logrus.Logger) *passwordHistoryRepository {
+	return &passwordHistoryRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *passwordHistoryRepository) Add(ctx context.Context, userID, passwordHash string) error {
+	query, args, err := psql.
+		Insert("password_history").
+		Columns("id", "user_id", "password_hash", "created_at").
+		Values(uuid.NewString(), userID, passwordHash, time.Now()).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Add",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to build SQL insert query")
+		return appErrors.NewAppError("SQL_BUILD_ERROR", "could not build password history insert query", err)
+	}
+
+	if _, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Add",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to execute insert query")
+		return appErrors.NewAppError("EXEC_ERROR", "could not store password history", err)
+	}
+
+	return nil
+}
+
+func (r *passwordHistoryRepository) ListRecent(ctx context.Context, userID string, limit int) ([]string, error) {
+	query, args, err := psql.
+		Select("password_hash").
+		From("password_history").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":  "ListRecent",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to build SQL select query")
+		return nil, appErrors.NewAppError("SQL_BUILD_ERROR", "could not build password history query", err)
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "ListRecent",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to execute select query")
+		return nil, appErrors.NewAppError("EXEC_ERROR", "could not list password history", err)
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
+			r.logger.WithFields(logrus.Fields{
+				"method":  "ListRecent",
+				"user_id": userID,
+				"error":   err,
+			}).Error("failed to scan password history row")
+			return nil, appErrors.NewAppError("SCAN_ERROR", "could not scan password history", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, appErrors.NewAppError("SCAN_ERROR", "could not read password history", err)
+	}
+
+	return hashes, nil
+}
+
+func (r *passwordHistoryRepository) Prune(ctx context.Context, userID string, keep int) (int64, error) {
+	newest := sq.Select("id").
+		From("password_history").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(keep))
+
+	query, args, err := psql.
+		Delete("password_history").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Expr("id NOT IN (?)", newest)).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Prune",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to build SQL delete query")
+		return 0, appErrors.NewAppError("SQL_BUILD_ERROR", "could not build password history prune query", err)
+	}
+
+	tag, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return 0, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Prune",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to execute delete query")
+		return 0, appErrors.NewAppError("EXEC_ERROR", "could not prune password history", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
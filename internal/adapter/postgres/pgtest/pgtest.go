@@ -0,0 +1,144 @@
+// Package pgtest connects repository tests to the database named by
+// TEST_DATABASE_URL. Tests using it are skipped when the variable is unset.
+package pgtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	apperrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// lockID serialises tests of different packages, which go test runs in
+// parallel against the same database.
+const lockID = 7_466_201
+
+// tables lists every application table. Truncating them leaves the
+// migration state alone.
+const tables = `users, customers, sellers, tokens, categories, products, product_images,
+	seller_api_tokens, audit_log, password_history, email_verifications`
+
+// New migrates the test database, empties it and returns a pool. The
+// database stays locked to the calling test until it ends.
+func New(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	lock, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := lock.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		lock.Release()
+		t.Fatalf("lock: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = lock.Exec(ctx, "SELECT pg_advisory_unlock($1)", lockID)
+		lock.Release()
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	if err := adapter.EnsureMigrated(dsn, migrationsPath(), true, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, "TRUNCATE "+tables+" CASCADE"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	return pool
+}
+
+// Seller inserts a seller user with the given id.
+func Seller(t *testing.T, pool *pgxpool.Pool, id string) {
+	t.Helper()
+	exec(t, pool, `INSERT INTO users (id, user_type, username, password_hash, email) VALUES ($1, 'seller', $1, '', $1 || '@example.com')`, id)
+	exec(t, pool, `INSERT INTO sellers (user_id) VALUES ($1)`, id)
+}
+
+// Customer inserts a customer user with the given id.
+func Customer(t *testing.T, pool *pgxpool.Pool, id string) {
+	t.Helper()
+	exec(t, pool, `INSERT INTO users (id, user_type, username, password_hash, email) VALUES ($1, 'customer', $1, '', $1 || '@example.com')`, id)
+	exec(t, pool, `INSERT INTO customers (user_id) VALUES ($1)`, id)
+}
+
+// Category inserts a top-level category with the given id.
+func Category(t *testing.T, pool *pgxpool.Pool, id string) {
+	t.Helper()
+	exec(t, pool, `INSERT INTO categories (id, name) VALUES ($1, $1)`, id)
+}
+
+// Product inserts an active product of sellerID in categoryID. Both must
+// exist.
+func Product(t *testing.T, pool *pgxpool.Pool, id, sellerID, categoryID string) {
+	t.Helper()
+	exec(t, pool, `INSERT INTO products (id, seller_id, title, title_normalized, title_key, price, category_id)
+		VALUES ($1, $2, $1, $1, $1, 10, $3)`, id, sellerID, categoryID)
+}
+
+func exec(t *testing.T, pool *pgxpool.Pool, sql string, args ...any) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), sql, args...); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+// AssertNotFound fails the test unless err is a NOT_FOUND AppError wrapping
+// ErrNotFound, the contract of every Get method.
+func AssertNotFound(t *testing.T, err error) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code() != "NOT_FOUND" || !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("error = %v, want a NOT_FOUND error wrapping ErrNotFound", err)
+	}
+}
+
+// Lookup looks up a row that does not exist.
+type Lookup func(ctx context.Context) error
+
+// Missing adapts a single-key getter to a Lookup of key.
+func Missing[T any](get func(context.Context, string) (T, error), key string) Lookup {
+	return func(ctx context.Context) error {
+		_, err := get(ctx, key)
+		return err
+	}
+}
+
+// AssertLookupsNotFound runs each lookup as a subtest and asserts it fails
+// with NOT_FOUND.
+func AssertLookupsNotFound(t *testing.T, lookups map[string]Lookup) {
+	t.Helper()
+	for name, lookup := range lookups {
+		t.Run(name, func(t *testing.T) {
+			AssertNotFound(t, lookup(context.Background()))
+		})
+	}
+}
+
+// migrationsPath is the migrations directory at the repository root.
+func migrationsPath() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "migrations")
+}
@@ -2,14 +2,128 @@ package product
 
 import (
 	"context"
+	"database/sql"
 	"marketplace/internal/entity"
 )
 
+// ProductRepository reads and updates skip soft deleted products.
 type ProductRepository interface {
+	// Create, CreateMany and Update return a DUPLICATE error when another
+	// product holds the same TitleKey.
 	Create(ctx context.Context, product *entity.Product) error
 	GetByID(ctx context.Context, id string) (*entity.Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error)
+	// Exists reports whether a row with field = value exists. field is a
+	// column name and must never come from user input.
+	Exists(ctx context.Context, field, value string) (bool, error)
+	// GetByTitle looks a product up by its normalized title. Inactive
+	// products are included, since titles stay unique across them.
 	GetByTitle(ctx context.Context, title string) (*entity.Product, error)
+	GetByTitleAndSeller(ctx context.Context, title, sellerID string) (*entity.Product, error)
+	GetByTitleAndCategory(ctx context.Context, title, categoryID string) (*entity.Product, error)
+	// Update writes the seller-editable fields and bumps the product
+	// version; is_active, featured and moderation have their own methods.
+	// When product.Version is set, the row is only updated if its version
+	// still matches; otherwise the error wraps errors.ErrConflict.
 	Update(ctx context.Context, product *entity.Product) error
+	// Delete is a soft delete: it sets deleted_at and keeps the row, so
+	// images and other references stay intact. is_active is left alone; it
+	// only tracks activation and moderation.
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, categoryID string, limit, offset int) ([]entity.Product, error)
+	// HardDelete removes the row, soft deleted or not. It is for admins only.
+	HardDelete(ctx context.Context, id string) error
+	// SetActiveByCategory sets is_active on every product of the category and
+	// returns how many rows changed.
+	SetActiveByCategory(ctx context.Context, categoryID string, active bool) (int64, error)
+	// List and Search only return approved products.
+	// CreateMany inserts all products in one transaction, so either all or
+	// none are stored.
+	CreateMany(ctx context.Context, products []entity.Product) error
+	// List returns one page and the number of products matching the filter
+	// across all pages.
+	List(ctx context.Context, categoryID string, filter ListFilter, limit, offset int) ([]entity.Product, int64, error)
+	// StatsByCategory computes CategoryStats in a single aggregate query.
+	StatsByCategory(ctx context.Context, categoryID string) (*CategoryStats, error)
+	SetFeatured(ctx context.Context, id string, featured bool) error
+	// ListBySeller returns the seller's products, newest first. A non-empty
+	// status limits them to that moderation status.
+	ListBySeller(ctx context.Context, sellerID, status string, limit, offset int) ([]entity.Product, error)
+	// ListBySellerAfter returns up to limit of the seller's products with an
+	// id greater than afterID, ordered by id. Pass the last id of a page to
+	// get the next one; an empty afterID starts from the beginning.
+	ListBySellerAfter(ctx context.Context, sellerID, afterID string, limit int) ([]entity.Product, error)
+	// ListByModerationStatus returns products in the status, oldest first.
+	ListByModerationStatus(ctx context.Context, status string, limit, offset int) ([]entity.Product, error)
+	// SetModeration sets the moderation status and reason. Approved products
+	// become active, others inactive.
+	SetModeration(ctx context.Context, id, status, reason string) error
+	// ListFeatured returns featured active products, newest first, and how
+	// many there are across all pages.
+	ListFeatured(ctx context.Context, limit, offset int) ([]entity.Product, int64, error)
+	// ListPopular returns active approved products, most viewed first.
+	ListPopular(ctx context.Context, limit, offset int) ([]entity.Product, error)
+	// IncrementViews adds each count to the view_count of its product id in
+	// a single statement. Unknown ids are ignored.
+	IncrementViews(ctx context.Context, views map[string]int64) error
+	// Search returns active products whose title or description contains
+	// query, case-insensitively. query is matched literally. inStockOnly
+	// skips products with no stock, as ListFilter.InStockOnly does for List.
+	Search(ctx context.Context, query string, inStockOnly bool, limit, offset int) ([]entity.Product, error)
+	// CountBySellerStatus counts the seller's active and inactive products in
+	// a single query.
+	CountBySellerStatus(ctx context.Context, sellerID string) (*StatusCounts, error)
+	// GetCard loads a product with its category name and seller company in
+	// one query. It returns a NOT_FOUND error when the product does not exist.
+	GetCard(ctx context.Context, id string) (*Card, error)
+}
+
+// ListFilter holds optional predicates for List. Zero values add no filter.
+type ListFilter struct {
+	InStockOnly bool
+	// MinPrice and MaxPrice bound the price inclusively; nil leaves that
+	// side open.
+	MinPrice *float64
+	MaxPrice *float64
+	// IsActive keeps only products with that is_active value. Nil means
+	// active products only: unlike the other fields, the zero value filters,
+	// so a caller never sees deactivated products without asking for them.
+	IsActive *bool
+	Sort     Sort
+	// SellerID, when set, keeps only that seller's products.
+	SellerID string
+}
+
+// Sort selects the List order. The zero value is newest first.
+type Sort int
+
+const (
+	SortNewest Sort = iota
+	SortPriceAsc
+	SortPriceDesc
+	SortTitle
+)
+
+// StatusCounts is the number of a seller's products per is_active state.
+type StatusCounts struct {
+	Active   int64
+	Inactive int64
+}
+
+// CategoryStats aggregates the products of one category. Prices are zero
+// when the category has no products.
+type CategoryStats struct {
+	Count    int64
+	Active   int64
+	AvgPrice float64
+	MinPrice float64
+	MaxPrice float64
+}
+
+// Card is the product summary shown in list and grid views.
+type Card struct {
+	ID            string
+	Title         string
+	Price         float64
+	CategoryName  string
+	SellerCompany sql.NullString
 }
@@ -2,11 +2,16 @@ package product
 
 import (
 	"context"
+	errorsLib "errors"
 	"marketplace/internal/entity"
 	"marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
@@ -17,26 +22,67 @@ const (
 	errCodeBuildQuery = "BUILD_QUERY"
 	errCodeExecQuery  = "EXEC_QUERY"
 	errCodeScanErr    = "SCAN_ERR"
-	errCodeAcquire    = "ACQUIRE_CONN"
 	errCodeBeginTx    = "BEGIN_TX"
 	errCodeCommitTx   = "COMMIT_TX"
 	errCodeRollbackTx = "ROLLBACK_TX"
+	errCodeDuplicate  = "DUPLICATE"
+
+	// pgUniqueViolation is the SQLSTATE of a unique index violation.
+	pgUniqueViolation = "23505"
 )
 
 var productColumns = []string{
 	"id",
 	"seller_id",
 	"title",
+	"title_normalized",
+	"title_key",
 	"description",
 	"price",
 	"created_at",
 	"updated_at",
 	"category_id",
 	"is_active",
+	"stock",
+	"version",
+	"featured",
+	"moderation_status",
+	"moderation_reason",
+	"view_count",
+}
+
+// productSelectColumns is productColumns plus the read-only primary image
+// URL, looked up per row from product_images.
+var productSelectColumns = append(append([]string{}, productColumns...),
+	"(SELECT pi.url FROM product_images pi WHERE pi.product_id = products.id AND pi.is_primary) AS primary_image_url",
+)
+
+// approvedOnly limits public listings to products that passed moderation.
+var approvedOnly = sq.Eq{"moderation_status": entity.ModerationApproved}
+
+// notDeleted excludes soft deleted products.
+var notDeleted = sq.Eq{"deleted_at": nil}
+
+// createManyBatch is the rows per INSERT in CreateMany, well below the
+// 65535 bind parameter limit of the protocol.
+const createManyBatch = 1000
+
+// defaultListOrder is the listing order when the caller asks for none.
+var defaultListOrder = []string{"created_at DESC"}
+
+// sortOrders maps a Sort to its ORDER BY keys. Unknown values fall back to
+// defaultListOrder, so only these strings ever reach the query.
+var sortOrders = map[Sort][]string{
+	SortNewest:    defaultListOrder,
+	SortPriceAsc:  {"price ASC"},
+	SortPriceDesc: {"price DESC"},
+	SortTitle:     {"title_normalized ASC"},
 }
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var _ ProductRepository = (*productRepository)(nil)
+
 type productRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -54,17 +100,7 @@ func (s *productRepository) Create(ctx context.Context, product *entity.Product)
 		query, args, err := psql.
 			Insert(tableProducts).
 			Columns(productColumns...).
-			Values(
-				product.ID,
-				product.SellerID,
-				product.Title,
-				product.Description,
-				product.Price,
-				product.CreatedAt,
-				product.UpdatedAt,
-				product.CategoryID,
-				product.IsActive,
-			).
+			Values(productValues(product)...).
 			ToSql()
 		if err != nil {
 			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
@@ -72,6 +108,12 @@ func (s *productRepository) Create(ctx context.Context, product *entity.Product)
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			if isUniqueViolation(err) {
+				return errors.NewAppError(errCodeDuplicate, "product already exists", err)
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute create query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -87,34 +129,124 @@ func (s *productRepository) Create(ctx context.Context, product *entity.Product)
 	})
 }
 
+func (s *productRepository) CreateMany(ctx context.Context, products []entity.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		for start := 0; start < len(products); start += createManyBatch {
+			end := min(start+createManyBatch, len(products))
+
+			builder := psql.
+				Insert(tableProducts).
+				Columns(productColumns...)
+			for i := start; i < end; i++ {
+				builder = builder.Values(productValues(&products[i])...)
+			}
+
+			query, args, err := builder.ToSql()
+			if err != nil {
+				return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+			}
+
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				if ctxErr := errors.FromContext(err); ctxErr != nil {
+					return ctxErr
+				}
+				if isUniqueViolation(err) {
+					return errors.NewAppError(errCodeDuplicate, "product already exists", err)
+				}
+				s.logger.WithFields(logrus.Fields{
+					"operation": "create_many",
+					"count":     end - start,
+					"error":     err,
+				}).Error("Failed to execute query")
+				return errors.NewAppError(errCodeExecQuery, "failed execute create query", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// productValues lists the insert values of p in productColumns order.
+func productValues(p *entity.Product) []interface{} {
+	return []interface{}{
+		p.ID,
+		p.SellerID,
+		p.Title,
+		p.TitleNormalized,
+		p.TitleKey,
+		p.Description,
+		p.Price,
+		p.CreatedAt,
+		p.UpdatedAt,
+		p.CategoryID,
+		p.IsActive,
+		p.Stock,
+		p.Version,
+		p.Featured,
+		p.ModerationStatus,
+		p.ModerationReason,
+		p.ViewCount,
+	}
+}
+
 func (s *productRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
-	return s.getBy(ctx, "id", id)
+	return s.getBy(ctx, sq.Eq{"id": id})
 }
 
 func (s *productRepository) GetByTitle(ctx context.Context, title string) (*entity.Product, error) {
-	return s.getBy(ctx, "title", title)
+	return s.getBy(ctx, sq.Eq{"title_normalized": title})
+}
+
+func (s *productRepository) GetByTitleAndSeller(ctx context.Context, title, sellerID string) (*entity.Product, error) {
+	return s.getBy(ctx, sq.Eq{"title_normalized": title, "seller_id": sellerID})
+}
+
+func (s *productRepository) GetByTitleAndCategory(ctx context.Context, title, categoryID string) (*entity.Product, error) {
+	return s.getBy(ctx, sq.Eq{"title_normalized": title, "category_id": categoryID})
 }
 
 func (s *productRepository) Update(ctx context.Context, product *entity.Product) error {
 	return s.withTx(ctx, func(tx pgx.Tx) error {
-		query, args, err := psql.
+		builder := psql.
 			Update(tableProducts).
 			Set("title", product.Title).
+			Set("title_normalized", product.TitleNormalized).
+			Set("title_key", product.TitleKey).
 			Set("description", product.Description).
 			Set("price", product.Price).
 			Set("updated_at", product.UpdatedAt).
 			Set("category_id", product.CategoryID).
-			Set("is_active", product.IsActive).
+			Set("stock", product.Stock).
+			Set("version", sq.Expr("version + 1")).
 			Where(sq.Eq{"id": product.ID}).
-			ToSql()
+			Where(notDeleted)
+		// A known version turns the update into a compare-and-set.
+		if product.Version > 0 {
+			builder = builder.Where(sq.Eq{"version": product.Version})
+		}
+
+		query, args, err := builder.ToSql()
 		if err != nil {
 			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
 		}
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			if isUniqueViolation(err) {
+				return errors.NewAppError(errCodeDuplicate, "product already exists", err)
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute update query", err)
 		}
+		if tag.RowsAffected() == 0 && product.Version > 0 {
+			return errors.NewAppError("CONFLICT", "product version mismatch", errors.ErrConflict)
+		}
 		if tag.RowsAffected() == 0 {
 			s.logger.WithFields(logrus.Fields{
 				"operation":  "update",
@@ -128,11 +260,17 @@ func (s *productRepository) Update(ctx context.Context, product *entity.Product)
 	})
 }
 
-func (s *productRepository) Delete(ctx context.Context, id string) error {
-	return s.withTx(ctx, func(tx pgx.Tx) error {
+func (s *productRepository) SetActiveByCategory(ctx context.Context, categoryID string, active bool) (int64, error) {
+	var updated int64
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
 		query, args, err := psql.
-			Delete(tableProducts).
-			Where(sq.Eq{"id": id}).
+			Update(tableProducts).
+			Set("is_active", active).
+			Set("updated_at", time.Now()).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"category_id": categoryID}).
+			Where(sq.NotEq{"is_active": active}).
+			Where(notDeleted).
 			ToSql()
 		if err != nil {
 			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
@@ -140,117 +278,541 @@ func (s *productRepository) Delete(ctx context.Context, id string) error {
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
-			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
-		}
-		if tag.RowsAffected() == 0 {
-			s.logger.WithFields(logrus.Fields{
-				"operation": "delete",
-				"id":        id,
-				"query":     query,
-				"args":      args,
-			}).Warn("No rows affected during delete")
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute set active query", err)
 		}
+		updated = tag.RowsAffected()
 
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
 }
 
-func (s *productRepository) List(ctx context.Context, categoryID string, limit, offset int) ([]entity.Product, error) {
-	builder := psql.
-		Select(productColumns...).
+func (s *productRepository) List(ctx context.Context, categoryID string, filter ListFilter, limit, offset int) ([]entity.Product, int64, error) {
+	orderBy, ok := sortOrders[filter.Sort]
+	if !ok {
+		orderBy = defaultListOrder
+	}
+
+	return s.queryPage(ctx, "list", listWhere(categoryID, filter), orderBy, limit, offset)
+}
+
+// queryPage selects one page of the products matching where together with
+// the number of matching rows, counted by a window function in the same
+// query. A page past the end has no row to carry the count, so only then
+// is it counted separately.
+func (s *productRepository) queryPage(ctx context.Context, operation string, where sq.Sqlizer, orderBy []string, limit, offset int) ([]entity.Product, int64, error) {
+	builder := orderWithTiebreaker(psql.
+		Select(append(append([]string{}, productSelectColumns...), "COUNT(*) OVER()")...).
 		From(tableProducts).
+		Where(where).
 		Limit(uint64(limit)).
-		Offset(uint64(offset))
+		Offset(uint64(offset)), orderBy...)
+
+	var total int64
+	products, err := s.queryProducts(ctx, operation, builder, &total)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(products) > 0 || offset == 0 {
+		return products, total, nil
+	}
+
+	total, err = s.count(ctx, operation, where)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
 
+// count returns the number of products matching where.
+func (s *productRepository) count(ctx context.Context, operation string, where sq.Sqlizer) (int64, error) {
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From(tableProducts).
+		Where(where).
+		ToSql()
+	if err != nil {
+		return 0, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	var count int64
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return 0, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": operation,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to scan query row")
+		return 0, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+	}
+
+	return count, nil
+}
+
+// listWhere is the predicate of List, shared by its page and its count so
+// the total always counts the rows the pages are cut from.
+func listWhere(categoryID string, filter ListFilter) sq.And {
+	where := sq.And{notDeleted, approvedOnly}
 	if categoryID != "" {
-		builder = builder.Where(sq.Eq{"category_id": categoryID})
+		where = append(where, sq.Eq{"category_id": categoryID})
+	}
+	if filter.InStockOnly {
+		where = append(where, sq.Gt{"stock": 0})
+	}
+	if filter.MinPrice != nil {
+		where = append(where, sq.GtOrEq{"price": *filter.MinPrice})
+	}
+	if filter.MaxPrice != nil {
+		where = append(where, sq.LtOrEq{"price": *filter.MaxPrice})
+	}
+	if filter.IsActive != nil {
+		where = append(where, sq.Eq{"is_active": *filter.IsActive})
+	} else {
+		where = append(where, sq.Eq{"is_active": true})
 	}
+	if filter.SellerID != "" {
+		where = append(where, sq.Eq{"seller_id": filter.SellerID})
+	}
+	return where
+}
 
-	query, args, err := builder.ToSql()
+func (s *productRepository) StatsByCategory(ctx context.Context, categoryID string) (*CategoryStats, error) {
+	query, args, err := psql.
+		Select(
+			"COUNT(*)",
+			"COUNT(*) FILTER (WHERE is_active)",
+			"COALESCE(AVG(price), 0)",
+			"COALESCE(MIN(price), 0)",
+			"COALESCE(MAX(price), 0)",
+		).
+		From(tableProducts).
+		Where(sq.Eq{"category_id": categoryID}).
+		Where(notDeleted).
+		ToSql()
 	if err != nil {
 		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
-	if err != nil {
+	var stats CategoryStats
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(
+		&stats.Count,
+		&stats.Active,
+		&stats.AvgPrice,
+		&stats.MinPrice,
+		&stats.MaxPrice,
+	); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		s.logger.WithFields(logrus.Fields{
-			"operation":   "list",
+			"operation":   "stats_by_category",
 			"category_id": categoryID,
-			"limit":       limit,
-			"offset":      offset,
 			"query":       query,
 			"args":        args,
 			"error":       err,
-		}).Error("Failed to execute list query")
-		return nil, errors.NewAppError(errCodeExecQuery, "failed execute list query", err)
+		}).Error("Failed to scan query row")
+		return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
 	}
-	defer rows.Close()
 
-	var products []entity.Product
-	for rows.Next() {
-		var p entity.Product
-		if err := rows.Scan(
-			&p.ID,
-			&p.SellerID,
-			&p.Title,
-			&p.Description,
-			&p.Price,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-			&p.CategoryID,
-			&p.IsActive,
-		); err != nil {
-			s.logger.WithFields(logrus.Fields{
-				"operation": "list",
-				"error":     err,
-			}).Error("Failed to scan query row")
-			return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+	return &stats, nil
+}
+
+func (s *productRepository) CountBySellerStatus(ctx context.Context, sellerID string) (*StatusCounts, error) {
+	query, args, err := psql.
+		Select(
+			"COUNT(*) FILTER (WHERE is_active)",
+			"COUNT(*) FILTER (WHERE NOT is_active)",
+		).
+		From(tableProducts).
+		Where(sq.Eq{"seller_id": sellerID}).
+		Where(notDeleted).
+		ToSql()
+	if err != nil {
+		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	var counts StatusCounts
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(&counts.Active, &counts.Inactive); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
 		}
-		products = append(products, p)
+		s.logger.WithFields(logrus.Fields{
+			"operation": "count_by_seller_status",
+			"seller_id": sellerID,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to scan query row")
+		return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
 	}
 
-	if err := rows.Err(); err != nil {
+	return &counts, nil
+}
+
+func (s *productRepository) GetCard(ctx context.Context, id string) (*Card, error) {
+	query, args, err := psql.
+		Select("p.id", "p.title", "p.price", "c.name", "s.company_name").
+		From(tableProducts + " p").
+		Join("categories c ON c.id = p.category_id").
+		LeftJoin("sellers s ON s.user_id = p.seller_id").
+		Where(sq.Eq{"p.id": id, "p.deleted_at": nil}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	var card Card
+	err = adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(
+		&card.ID,
+		&card.Title,
+		&card.Price,
+		&card.CategoryName,
+		&card.SellerCompany,
+	)
+	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err == pgx.ErrNoRows {
+			return nil, errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+		}
 		s.logger.WithFields(logrus.Fields{
-			"operation": "list",
+			"operation": "get_card",
+			"id":        id,
+			"query":     query,
+			"args":      args,
 			"error":     err,
-		}).Error("Error after scanning rows")
-		return nil, errors.NewAppError(errCodeScanErr, "error after scanning rows", err)
+		}).Error("Failed to scan query row")
+		return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
 	}
 
-	return products, nil
+	return &card, nil
 }
 
-func (s *productRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
-	conn, err := s.pool.Acquire(ctx)
+func (s *productRepository) Delete(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Update(tableProducts).
+			Set("deleted_at", time.Now()).
+			Set("updated_at", time.Now()).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"id": id}).
+			Where(notDeleted).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute soft delete query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+		}
+
+		return nil
+	})
+}
+
+func (s *productRepository) HardDelete(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Delete(tableProducts).
+			Where(sq.Eq{"id": id}).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+		}
+
+		return nil
+	})
+}
+func (s *productRepository) ListBySeller(ctx context.Context, sellerID, status string, limit, offset int) ([]entity.Product, error) {
+	builder := orderWithTiebreaker(psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"seller_id": sellerID}).
+		Where(notDeleted).
+		Limit(uint64(limit)).
+		Offset(uint64(offset)), defaultListOrder...)
+
+	if status != "" {
+		builder = builder.Where(sq.Eq{"moderation_status": status})
+	}
+
+	return s.queryProducts(ctx, "list_by_seller", builder)
+}
+
+func (s *productRepository) ListBySellerAfter(ctx context.Context, sellerID, afterID string, limit int) ([]entity.Product, error) {
+	builder := psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"seller_id": sellerID}).
+		Where(notDeleted).
+		OrderBy("id ASC").
+		Limit(uint64(limit))
+
+	if afterID != "" {
+		builder = builder.Where(sq.Gt{"id": afterID})
+	}
+
+	return s.queryProducts(ctx, "list_by_seller_after", builder)
+}
+
+func (s *productRepository) ListByModerationStatus(ctx context.Context, status string, limit, offset int) ([]entity.Product, error) {
+	builder := orderWithTiebreaker(psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"moderation_status": status}).
+		Where(notDeleted).
+		Limit(uint64(limit)).
+		Offset(uint64(offset)), "created_at ASC")
+
+	return s.queryProducts(ctx, "list_by_moderation_status", builder)
+}
+
+func (s *productRepository) SetModeration(ctx context.Context, id, status, reason string) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Update(tableProducts).
+			Set("moderation_status", status).
+			Set("moderation_reason", reason).
+			Set("is_active", status == entity.ModerationApproved).
+			Set("updated_at", time.Now()).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"id": id}).
+			Where(notDeleted).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute set moderation query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+		}
+
+		return nil
+	})
+}
+
+func (s *productRepository) SetFeatured(ctx context.Context, id string, featured bool) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Update(tableProducts).
+			Set("featured", featured).
+			Set("updated_at", time.Now()).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"id": id}).
+			Where(notDeleted).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute set featured query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+		}
+
+		return nil
+	})
+}
+
+func (s *productRepository) ListFeatured(ctx context.Context, limit, offset int) ([]entity.Product, int64, error) {
+	where := sq.And{sq.Eq{"featured": true, "is_active": true}, notDeleted, approvedOnly}
+	return s.queryPage(ctx, "list_featured", where, defaultListOrder, limit, offset)
+}
+
+func (s *productRepository) ListPopular(ctx context.Context, limit, offset int) ([]entity.Product, error) {
+	builder := orderWithTiebreaker(psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"is_active": true}).
+		Where(notDeleted).
+		Where(approvedOnly).
+		Limit(uint64(limit)).
+		Offset(uint64(offset)), "view_count DESC")
+
+	return s.queryProducts(ctx, "list_popular", builder)
+}
+
+func (s *productRepository) IncrementViews(ctx context.Context, views map[string]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for id, n := range views {
+		ids = append(ids, id)
+		counts = append(counts, n)
+	}
+
+	// One statement for the whole batch; unnest pairs ids with counts.
+	query := `UPDATE ` + tableProducts + ` AS p
+		SET view_count = p.view_count + v.n
+		FROM unnest($1::text[], $2::bigint[]) AS v(id, n)
+		WHERE p.id = v.id`
+
+	if _, err := adapter.QuerierFrom(ctx, s.pool).Exec(ctx, query, ids, counts); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": "increment_views",
+			"products":  len(ids),
+			"error":     err,
+		}).Error("Failed to execute query")
+		return errors.NewAppError(errCodeExecQuery, "failed execute increment views query", err)
+	}
+
+	return nil
+}
+
+func (s *productRepository) Search(ctx context.Context, query string, inStockOnly bool, limit, offset int) ([]entity.Product, error) {
+	pattern := "%" + escapeLike(query) + "%"
+	builder := psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"is_active": true}).
+		Where(notDeleted).
+		Where(approvedOnly).
+		Where(sq.Or{
+			sq.ILike{"title": pattern},
+			sq.ILike{"description": pattern},
+		})
+	if inStockOnly {
+		builder = builder.Where(sq.Gt{"stock": 0})
+	}
+	builder = orderWithTiebreaker(builder.
+		Limit(uint64(limit)).
+		Offset(uint64(offset)), defaultListOrder...)
+
+	return s.queryProducts(ctx, "search", builder)
+}
+
+// likeEscaper escapes the LIKE wildcards and the default escape character so
+// user input only matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+func (s *productRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	builder := psql.
+		Select(productSelectColumns...).
+		From(tableProducts).
+		Where(sq.Eq{"id": ids}).
+		Where(notDeleted)
+
+	return s.queryProducts(ctx, "get_by_ids", builder)
+}
+
+func (s *productRepository) Exists(ctx context.Context, field, value string) (bool, error) {
+	query, args, err := psql.
+		Select("1").
+		From(tableProducts).
+		Where(sq.Eq{field: value}).
+		Where(notDeleted).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
 	if err != nil {
-		return errors.NewAppError(errCodeAcquire, "failed to acquire connection", err)
+		return false, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	var exists bool
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return false, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": "exists",
+			"field":     field,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to scan query row")
+		return false, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
 	}
-	defer conn.Release()
 
-	tx, err := conn.Begin(ctx)
+	return exists, nil
+}
+
+func (s *productRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := adapter.QuerierFrom(ctx, s.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeBeginTx, "failed to begin transaction", err)
 	}
 
 	if err = fn(tx); err != nil {
-		if rbErr := tx.Rollback(ctx); rbErr != nil {
+		// A canceled context also fails the rollback; the original error
+		// already says why, and pgx discards the connection.
+		if rbErr := tx.Rollback(ctx); rbErr != nil && errors.FromContext(rbErr) == nil {
 			return errors.NewAppError(errCodeRollbackTx, "failed to rollback transaction", rbErr)
 		}
 		return err
 	}
 
 	if cmErr := tx.Commit(ctx); cmErr != nil {
+		if ctxErr := errors.FromContext(cmErr); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeCommitTx, "failed to commit transaction", cmErr)
 	}
 
 	return nil
 }
 
-func (s *productRepository) getBy(ctx context.Context, field string, value any) (*entity.Product, error) {
+func (s *productRepository) getBy(ctx context.Context, where sq.Eq) (*entity.Product, error) {
 	query, args, err := psql.
-		Select(productColumns...).
+		Select(productSelectColumns...).
 		From(tableProducts).
-		Where(sq.Eq{field: value}).
+		Where(where).
+		Where(notDeleted).
 		Limit(1).
 		ToSql()
 	if err != nil {
@@ -258,25 +820,17 @@ func (s *productRepository) getBy(ctx context.Context, field string, value any)
 	}
 
 	var p entity.Product
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
-		&p.ID,
-		&p.SellerID,
-		&p.Title,
-		&p.Description,
-		&p.Price,
-		&p.CreatedAt,
-		&p.UpdatedAt,
-		&p.CategoryID,
-		&p.IsActive,
-	)
+	err = scanProduct(adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...), &p)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
 		}
 		s.logger.WithFields(logrus.Fields{
 			"operation": "get_by",
-			"field":     field,
-			"value":     value,
+			"where":     where,
 			"query":     query,
 			"args":      args,
 			"error":     err,
@@ -286,3 +840,97 @@ func (s *productRepository) getBy(ctx context.Context, field string, value any)
 
 	return &p, nil
 }
+
+// queryProducts runs builder and scans each row with scanProduct. extra
+// receives any columns selected after productSelectColumns; they hold the
+// same value on every row, so the last row's values are kept.
+func (s *productRepository) queryProducts(ctx context.Context, operation string, builder sq.SelectBuilder, extra ...any) ([]entity.Product, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, s.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": operation,
+			"query":     query,
+			"args":      args,
+			"error":     err,
+		}).Error("Failed to execute query")
+		return nil, errors.NewAppError(errCodeExecQuery, "failed execute "+operation+" query", err)
+	}
+	defer rows.Close()
+
+	products := []entity.Product{}
+	for rows.Next() {
+		var p entity.Product
+		if err := scanProduct(rows, &p, extra...); err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
+			s.logger.WithFields(logrus.Fields{
+				"operation": operation,
+				"error":     err,
+			}).Error("Failed to scan query row")
+			return nil, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.WithFields(logrus.Fields{
+			"operation": operation,
+			"error":     err,
+		}).Error("Error after scanning rows")
+		return nil, errors.NewAppError(errCodeScanErr, "error after scanning rows", err)
+	}
+
+	return products, nil
+}
+
+// isUniqueViolation reports whether err is a unique index violation, which
+// on products means the title is taken within its scope.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errorsLib.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// orderWithTiebreaker applies orderBy and then id, so rows that tie on the
+// requested keys keep a stable order and pages neither repeat nor skip rows.
+func orderWithTiebreaker(builder sq.SelectBuilder, orderBy ...string) sq.SelectBuilder {
+	keys := append(append([]string{}, orderBy...), "id ASC")
+	return builder.OrderBy(keys...)
+}
+
+// scanProduct scans a row selected with productSelectColumns, followed by
+// the columns scanned into extra.
+func scanProduct(row pgx.Row, p *entity.Product, extra ...any) error {
+	dest := []any{
+		&p.ID,
+		&p.SellerID,
+		&p.Title,
+		&p.TitleNormalized,
+		&p.TitleKey,
+		&p.Description,
+		&p.Price,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.CategoryID,
+		&p.IsActive,
+		&p.Stock,
+		&p.Version,
+		&p.Featured,
+		&p.ModerationStatus,
+		&p.ModerationReason,
+		&p.ViewCount,
+		&p.PrimaryImageURL,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
@@ -0,0 +1,119 @@
+package product
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"marketplace/internal/entity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestRepository(t *testing.T) (*productRepository, *pgxpool.Pool) {
+	t.Helper()
+	pool := pgtest.New(t)
+	pgtest.Seller(t, pool, "seller-1")
+	pgtest.Category(t, pool, "c1")
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewProductRepository(pool, logger), pool
+}
+
+// create stores an active, approved product of seller-1 in c1.
+func create(t *testing.T, repo *productRepository, id string, price float64, createdAt time.Time) {
+	t.Helper()
+	err := repo.Create(context.Background(), &entity.Product{
+		ID:               id,
+		SellerID:         "seller-1",
+		CategoryID:       "c1",
+		Title:            id,
+		TitleNormalized:  id,
+		TitleKey:         id,
+		Price:            price,
+		CreatedAt:        createdAt,
+		UpdatedAt:        createdAt,
+		IsActive:         true,
+		Stock:            1,
+		Version:          1,
+		ModerationStatus: "approved",
+	})
+	if err != nil {
+		t.Fatalf("Create(%s): %v", id, err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	create(t, repo, "p1", 10, time.Now())
+
+	for value, want := range map[string]bool{"p1": true, "p2": false} {
+		exists, err := repo.Exists(context.Background(), "id", value)
+		if err != nil {
+			t.Fatalf("Exists(%s): %v", value, err)
+		}
+		if exists != want {
+			t.Errorf("Exists(%s) = %v, want %v", value, exists, want)
+		}
+	}
+}
+
+func TestOrderWithTiebreaker(t *testing.T) {
+	query, _, err := orderWithTiebreaker(psql.Select("id").From(tableProducts), "price ASC").ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %v", err)
+	}
+	if !strings.HasSuffix(query, "ORDER BY price ASC, id ASC") {
+		t.Errorf("query = %q, want it ordered by price then id", query)
+	}
+}
+
+func TestListPagesEqualPricesWithoutRepeats(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	createdAt := time.Now().Truncate(time.Second)
+	ids := []string{"p3", "p1", "p5", "p2", "p4"}
+	for _, id := range ids {
+		create(t, repo, id, 10, createdAt)
+	}
+
+	seen := map[string]bool{}
+	for offset := 0; offset < len(ids); offset += 2 {
+		page, total, err := repo.List(context.Background(), "c1", ListFilter{Sort: SortPriceAsc}, 2, offset)
+		if err != nil {
+			t.Fatalf("List(offset %d): %v", offset, err)
+		}
+		if total != int64(len(ids)) {
+			t.Errorf("offset %d: total = %d, want %d", offset, total, len(ids))
+		}
+		for _, p := range page {
+			if seen[p.ID] {
+				t.Errorf("offset %d: %s already listed on an earlier page", offset, p.ID)
+			}
+			seen[p.ID] = true
+		}
+	}
+	if len(seen) != len(ids) {
+		t.Errorf("listed %d products across pages, want %d", len(seen), len(ids))
+	}
+}
+
+func TestGetUnknownIsNotFound(t *testing.T) {
+	repo, _ := newTestRepository(t)
+
+	pgtest.AssertLookupsNotFound(t, map[string]pgtest.Lookup{
+		"GetByID":    pgtest.Missing(repo.GetByID, "missing"),
+		"GetByTitle": pgtest.Missing(repo.GetByTitle, "missing"),
+		"GetByTitleAndSeller": func(ctx context.Context) error {
+			_, err := repo.GetByTitleAndSeller(ctx, "missing", "seller-1")
+			return err
+		},
+		"GetByTitleAndCategory": func(ctx context.Context) error {
+			_, err := repo.GetByTitleAndCategory(ctx, "missing", "c1")
+			return err
+		},
+	})
+}
@@ -9,5 +9,13 @@ type ProductImageRepository interface {
 	Create(ctx context.Context, image *entity.ProductImage) error
 	GetByID(ctx context.Context, id string) (*entity.ProductImage, error)
 	Delete(ctx context.Context, id string) error
+	DeleteByProductID(ctx context.Context, productID string) (int64, error)
+	DeleteByIDs(ctx context.Context, ids []string) (int64, error)
 	ListByProductID(ctx context.Context, productID string, limit, offset int) ([]entity.ProductImage, error)
+	// ListByProductIDs loads the images of several products in one query,
+	// at most perProduct each when perProduct is positive.
+	ListByProductIDs(ctx context.Context, productIDs []string, perProduct int) ([]entity.ProductImage, error)
+	CountByProductID(ctx context.Context, productID string) (int64, error)
+	// SetPrimary makes imageID the only primary image of productID.
+	SetPrimary(ctx context.Context, imageID, productID string) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"marketplace/internal/entity"
 	"marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
@@ -17,7 +18,6 @@ const (
 	errCodeBuildQuery = "BUILD_QUERY"
 	errCodeExecQuery  = "EXEC_QUERY"
 	errCodeScanErr    = "SCAN_ERR"
-	errCodeAcquire    = "ACQUIRE_CONN"
 	errCodeBeginTx    = "BEGIN_TX"
 	errCodeCommitTx   = "COMMIT_TX"
 	errCodeRollbackTx = "ROLLBACK_TX"
@@ -28,10 +28,13 @@ var productImageColums = []string{
 	"product_id",
 	"url",
 	"created_at",
+	"is_primary",
 }
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var _ ProductImageRepository = (*productImageRepository)(nil)
+
 type productImageRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -54,6 +57,7 @@ func (s *productImageRepository) Create(ctx context.Context, image *entity.Produ
 				image.ProductID,
 				image.URL,
 				image.CreatedAt,
+				image.IsPrimary,
 			).
 			ToSql()
 		if err != nil {
@@ -62,6 +66,9 @@ func (s *productImageRepository) Create(ctx context.Context, image *entity.Produ
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute create query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -90,15 +97,19 @@ func (s *productImageRepository) GetByID(ctx context.Context, id string) (*entit
 	}
 
 	var i entity.ProductImage
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
+	err = adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(
 		&i.ID,
 		&i.ProductID,
 		&i.URL,
 		&i.CreatedAt,
+		&i.IsPrimary,
 	)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return nil, errors.NewAppError("NOT_FOUND", "image not found", errors.ErrNotFound)
 		}
 		s.logger.WithFields(logrus.Fields{
 			"operation": "get_by_id",
@@ -125,6 +136,9 @@ func (s *productImageRepository) Delete(ctx context.Context, id string) error {
 
 		tag, err := tx.Exec(ctx, query, args...)
 		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
 			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
 		}
 		if tag.RowsAffected() == 0 {
@@ -140,28 +154,147 @@ func (s *productImageRepository) Delete(ctx context.Context, id string) error {
 	})
 }
 
-func (s *productImageRepository) ListByProductID(ctx context.Context, productID string, limit, offset int) ([]entity.ProductImage, error) {
-	builder := psql.Select(productImageColums...).From(tableProductImages).Where(sq.Eq{"product_id": productID})
+func (s *productImageRepository) DeleteByProductID(ctx context.Context, productID string) (int64, error) {
+	var deleted int64
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Delete(tableProductImages).
+			Where(sq.Eq{"product_id": productID}).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
 
-	query, args, err := builder.ToSql()
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
+		}
+		deleted = tag.RowsAffected()
+
+		return nil
+	})
 	if err != nil {
-		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func (s *productImageRepository) DeleteByIDs(ctx context.Context, ids []string) (int64, error) {
+	var deleted int64
+	err := s.withTx(ctx, func(tx pgx.Tx) error {
+		query, args, err := psql.
+			Delete(tableProductImages).
+			Where(sq.Eq{"id": ids}).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute delete query", err)
+		}
+		deleted = tag.RowsAffected()
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	return deleted, nil
+}
+
+func (s *productImageRepository) CountByProductID(ctx context.Context, productID string) (int64, error) {
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From(tableProductImages).
+		Where(sq.Eq{"product_id": productID}).
+		ToSql()
 	if err != nil {
+		return 0, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	var count int64
+	if err := adapter.QuerierFrom(ctx, s.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return 0, ctxErr
+		}
 		s.logger.WithFields(logrus.Fields{
-			"operation":  "list",
+			"operation":  "count",
 			"product_id": productID,
 			"query":      query,
 			"args":       args,
 			"error":      err,
+		}).Error("Failed to scan query row")
+		return 0, errors.NewAppError(errCodeScanErr, "failed scan query row", err)
+	}
+
+	return count, nil
+}
+
+func (s *productImageRepository) ListByProductID(ctx context.Context, productID string, limit, offset int) ([]entity.ProductImage, error) {
+	builder := psql.Select(productImageColums...).
+		From(tableProductImages).
+		Where(sq.Eq{"product_id": productID}).
+		OrderBy("created_at ASC", "id ASC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	return s.listImages(ctx, builder, logrus.Fields{"product_id": productID})
+}
+
+// ListByProductIDs returns the images of productIDs grouped by product, at
+// most perProduct per product when perProduct is positive.
+func (s *productImageRepository) ListByProductIDs(ctx context.Context, productIDs []string, perProduct int) ([]entity.ProductImage, error) {
+	if len(productIDs) == 0 {
+		return []entity.ProductImage{}, nil
+	}
+
+	// The inner select keeps ? placeholders; the outer one numbers them.
+	ranked := sq.Select(productImageColums...).
+		Column("row_number() OVER (PARTITION BY product_id ORDER BY created_at ASC, id ASC) AS rn").
+		From(tableProductImages).
+		Where(sq.Eq{"product_id": productIDs})
+	builder := psql.Select(productImageColums...).
+		FromSelect(ranked, "ranked").
+		OrderBy("product_id ASC", "rn ASC")
+	if perProduct > 0 {
+		builder = builder.Where(sq.LtOrEq{"rn": perProduct})
+	}
+
+	return s.listImages(ctx, builder, logrus.Fields{"product_count": len(productIDs)})
+}
+
+func (s *productImageRepository) listImages(ctx context.Context, builder sq.SelectBuilder, fields logrus.Fields) ([]entity.ProductImage, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, s.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.WithFields(fields).WithFields(logrus.Fields{
+			"operation": "list",
+			"query":     query,
+			"args":      args,
+			"error":     err,
 		}).Error("Failed to execute query")
 		return nil, errors.NewAppError(errCodeExecQuery, "failed execute query list", err)
 	}
 	defer rows.Close()
 
-	var images []entity.ProductImage
+	images := []entity.ProductImage{}
 	for rows.Next() {
 		var i entity.ProductImage
 		if err := rows.Scan(
@@ -169,7 +302,11 @@ func (s *productImageRepository) ListByProductID(ctx context.Context, productID
 			&i.ProductID,
 			&i.URL,
 			&i.CreatedAt,
+			&i.IsPrimary,
 		); err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
 			s.logger.WithFields(logrus.Fields{
 				"operation": "list",
 				"error":     err,
@@ -180,6 +317,9 @@ func (s *productImageRepository) ListByProductID(ctx context.Context, productID
 	}
 
 	if err := rows.Err(); err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		s.logger.WithFields(logrus.Fields{
 			"operation": "list",
 			"error":     err,
@@ -190,26 +330,72 @@ func (s *productImageRepository) ListByProductID(ctx context.Context, productID
 	return images, nil
 }
 
-func (s *productImageRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
-	conn, err := s.pool.Acquire(ctx)
-	if err != nil {
-		return errors.NewAppError(errCodeAcquire, "failed to acquire connection", err)
-	}
-	defer conn.Release()
+func (s *productImageRepository) SetPrimary(ctx context.Context, imageID, productID string) error {
+	return s.withTx(ctx, func(tx pgx.Tx) error {
+		// Clear first: the partial unique index allows one primary per product.
+		query, args, err := psql.
+			Update(tableProductImages).
+			Set("is_primary", false).
+			Where(sq.Eq{"product_id": productID, "is_primary": true}).
+			Where(sq.NotEq{"id": imageID}).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute unset primary query", err)
+		}
 
-	tx, err := conn.Begin(ctx)
+		query, args, err = psql.
+			Update(tableProductImages).
+			Set("is_primary", true).
+			Where(sq.Eq{"id": imageID, "product_id": productID}).
+			ToSql()
+		if err != nil {
+			return errors.NewAppError(errCodeBuildQuery, "failed build query", err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			if ctxErr := errors.FromContext(err); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewAppError(errCodeExecQuery, "failed execute set primary query", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.NewAppError("NOT_FOUND", "image not found", errors.ErrNotFound)
+		}
+
+		return nil
+	})
+}
+
+func (s *productImageRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := adapter.QuerierFrom(ctx, s.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := errors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeBeginTx, "failed to begin transaction", err)
 	}
 
 	if err = fn(tx); err != nil {
-		if rbErr := tx.Rollback(ctx); rbErr != nil {
+		// A canceled context also fails the rollback; the original error
+		// already says why, and pgx discards the connection.
+		if rbErr := tx.Rollback(ctx); rbErr != nil && errors.FromContext(rbErr) == nil {
 			return errors.NewAppError(errCodeRollbackTx, "failed to rollback transaction", rbErr)
 		}
 		return err
 	}
 
 	if cmErr := tx.Commit(ctx); cmErr != nil {
+		if ctxErr := errors.FromContext(cmErr); ctxErr != nil {
+			return ctxErr
+		}
 		return errors.NewAppError(errCodeCommitTx, "failed to commit transaction", cmErr)
 	}
 
@@ -0,0 +1,116 @@
+package productimage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"marketplace/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestRepository(t *testing.T) *productImageRepository {
+	t.Helper()
+	pool := pgtest.New(t)
+	pgtest.Seller(t, pool, "seller-1")
+	pgtest.Category(t, pool, "c1")
+	pgtest.Product(t, pool, "p1", "seller-1", "c1")
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewProductImageRepository(pool, logger)
+}
+
+func TestListByProductIDPages(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	start := time.Now().Truncate(time.Second)
+	for i := 0; i < 25; i++ {
+		err := repo.Create(ctx, &entity.ProductImage{
+			ID:        fmt.Sprintf("img-%02d", i),
+			ProductID: "p1",
+			URL:       fmt.Sprintf("https://cdn.example.com/%02d.png", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Second),
+		})
+		if err != nil {
+			t.Fatalf("Create(%d): %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		limit, offset int
+		first         string
+		count         int
+	}{
+		{20, 0, "img-00", 20},
+		{20, 20, "img-20", 5},
+		{10, 10, "img-10", 10},
+		{20, 25, "", 0},
+	}
+
+	for _, tt := range tests {
+		images, err := repo.ListByProductID(ctx, "p1", tt.limit, tt.offset)
+		if err != nil {
+			t.Fatalf("ListByProductID(%d, %d): %v", tt.limit, tt.offset, err)
+		}
+		if len(images) != tt.count {
+			t.Errorf("ListByProductID(%d, %d) returned %d images, want %d", tt.limit, tt.offset, len(images), tt.count)
+			continue
+		}
+		if tt.count > 0 && images[0].ID != tt.first {
+			t.Errorf("ListByProductID(%d, %d) starts at %s, want %s", tt.limit, tt.offset, images[0].ID, tt.first)
+		}
+	}
+}
+
+func TestListByProductIDs(t *testing.T) {
+	repo := newTestRepository(t)
+	pgtest.Product(t, repo.pool, "p2", "seller-1", "c1")
+	pgtest.Product(t, repo.pool, "p3", "seller-1", "c1")
+	ctx := context.Background()
+	start := time.Now().Truncate(time.Second)
+	for i, productID := range []string{"p1", "p2", "p1", "p2", "p1"} {
+		err := repo.Create(ctx, &entity.ProductImage{
+			ID:        fmt.Sprintf("img-%d", i),
+			ProductID: productID,
+			URL:       fmt.Sprintf("https://cdn.example.com/%d.png", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Second),
+		})
+		if err != nil {
+			t.Fatalf("Create(%d): %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		perProduct int
+		want       []string
+	}{
+		{0, []string{"img-0", "img-2", "img-4", "img-1", "img-3"}},
+		{2, []string{"img-0", "img-2", "img-1", "img-3"}},
+		{1, []string{"img-0", "img-1"}},
+	}
+
+	for _, tt := range tests {
+		images, err := repo.ListByProductIDs(ctx, []string{"p1", "p2", "p3"}, tt.perProduct)
+		if err != nil {
+			t.Fatalf("ListByProductIDs(%d): %v", tt.perProduct, err)
+		}
+		got := make([]string, len(images))
+		for i, image := range images {
+			got[i] = image.ID
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ListByProductIDs(%d) = %v, want %v", tt.perProduct, got, tt.want)
+		}
+	}
+}
+
+func TestGetByIDUnknownIsNotFound(t *testing.T) {
+	repo := newTestRepository(t)
+
+	_, err := repo.GetByID(context.Background(), "missing")
+	pgtest.AssertNotFound(t, err)
+}
@@ -7,6 +7,7 @@ import (
 
 type SellerRepository interface {
 	UpdateProfile(ctx context.Context, profile *entity.SellerProfile) error
+	GetByID(ctx context.Context, userID string) (*entity.SellerProfile, error)
 	GetByUsername(ctx context.Context, username string) (*entity.SellerProfile, error)
 	GetByEmail(ctx context.Context, email string) (*entity.SellerProfile, error)
 }
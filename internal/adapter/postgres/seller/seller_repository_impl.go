@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"marketplace/internal/entity"
 	appError "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,6 +14,8 @@ import (
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var _ SellerRepository = (*sellerRepository)(nil)
+
 type sellerRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -23,8 +26,11 @@ func NewSellerRepository(pool *pgxpool.Pool, logger *logrus.Logger) *sellerRepos
 }
 
 func (r *sellerRepository) UpdateProfile(ctx context.Context, profile *entity.SellerProfile) (err error) {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := adapter.QuerierFrom(ctx, r.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return appError.NewAppError("TX_BEGIN_FAIL", "could not begin transaction", err)
 	}
 	defer func() {
@@ -33,6 +39,10 @@ func (r *sellerRepository) UpdateProfile(ctx context.Context, profile *entity.Se
 				r.logger.WithError(rbErr).Error("failed to rollback tx")
 			}
 		} else if cmErr := tx.Commit(ctx); cmErr != nil {
+			if ctxErr := appError.FromContext(cmErr); ctxErr != nil {
+				err = ctxErr
+				return
+			}
 			err = appError.NewAppError("TX_COMMIT_FAIL", "could not commit transaction", cmErr)
 		}
 	}()
@@ -49,6 +59,9 @@ func (r *sellerRepository) UpdateProfile(ctx context.Context, profile *entity.Se
 	}
 
 	if _, err = tx.Exec(ctx, sQuery, sArgs...); err != nil { // tx!
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return appError.NewAppError("EXEC_ERROR", "could not execute customer update", err)
 	}
 
@@ -63,6 +76,9 @@ func (r *sellerRepository) UpdateProfile(ctx context.Context, profile *entity.Se
 	}
 
 	if _, err = tx.Exec(ctx, uQuery, uArgs...); err != nil { // tx!
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		return appError.NewAppError("EXEC_ERROR", "could not execute user update", err)
 	}
 
@@ -70,6 +86,10 @@ func (r *sellerRepository) UpdateProfile(ctx context.Context, profile *entity.Se
 	return nil
 }
 
+func (r *sellerRepository) GetByID(ctx context.Context, userID string) (*entity.SellerProfile, error) {
+	return r.getByField(ctx, "id", userID)
+}
+
 func (r *sellerRepository) GetByUsername(ctx context.Context, username string) (*entity.SellerProfile, error) {
 	return r.getByField(ctx, "username", username)
 }
@@ -86,7 +106,7 @@ func (r *sellerRepository) getByField(ctx context.Context, field, value string)
 		).
 		From("users u").
 		Join("sellers s ON u.id = s.user_id").
-		Where(sq.Eq{fmt.Sprintf("u.%s", field): value}).
+		Where(sq.Eq{fmt.Sprintf("u.%s", field): value, "u.deleted_at": nil}).
 		ToSql()
 	if err != nil {
 		r.logger.WithError(err).Error("failed to build getByField query")
@@ -94,11 +114,14 @@ func (r *sellerRepository) getByField(ctx context.Context, field, value string)
 	}
 
 	var s entity.SellerProfile
-	row := r.pool.QueryRow(ctx, query, args...)
+	row := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...)
 	if err := row.Scan(
 		&s.ID, &s.Username, &s.PasswordHash, &s.Email,
 		&s.UpdatedAt, &s.CreatedAt, &s.CompanyName, &s.Rating,
 	); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		r.logger.WithError(err).Warn("seller not found")
 		return nil, appError.NewAppError("NOT_FOUND", "seller not found", appError.ErrNotFound)
 	}
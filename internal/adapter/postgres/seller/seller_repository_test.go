@@ -0,0 +1,22 @@
+package seller
+
+import (
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetUnknownIsNotFound(t *testing.T) {
+	pool := pgtest.New(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := NewSellerRepository(pool, logger)
+
+	pgtest.AssertLookupsNotFound(t, map[string]pgtest.Lookup{
+		"GetByID":       pgtest.Missing(repo.GetByID, "missing"),
+		"GetByEmail":    pgtest.Missing(repo.GetByEmail, "nobody@example.com"),
+		"GetByUsername": pgtest.Missing(repo.GetByUsername, "nobody"),
+	})
+}
@@ -3,9 +3,25 @@ package token
 import (
 	"context"
 	"marketplace/internal/entity"
+	"time"
 )
 
 type TokenRepository interface {
-	GetRefreshTokenByUserID(ctx context.Context, user_id string) (*entity.RefreshToken, error)
+	GetByToken(ctx context.Context, token string) (*entity.RefreshToken, error)
+	// GetByID loads a refresh token by its session id.
+	GetByID(ctx context.Context, id string) (*entity.RefreshToken, error)
+	// UpsertRefreshToken joins the caller's transaction when ctx carries one.
 	UpsertRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+	// ListSessionsByUserID returns the user's refresh tokens that are neither
+	// revoked nor expired, newest first.
+	ListSessionsByUserID(ctx context.Context, userID string) ([]entity.RefreshToken, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	// RevokeToken revokes one refresh token by id and reports whether it was
+	// still active. Concurrent callers see true at most once.
+	RevokeToken(ctx context.Context, tokenID string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID string) (int64, error)
+	// RevokeOldestSessions revokes every active session of the user except
+	// the newest keep ones and returns how many were revoked.
+	RevokeOldestSessions(ctx context.Context, userID string, keep int) (int64, error)
+	TouchSession(ctx context.Context, sessionID string, usedAt time.Time) error
 }
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"marketplace/internal/entity"
 	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 
@@ -15,6 +17,21 @@ import (
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var tokenColumns = []string{
+	"id",
+	"user_id",
+	"token",
+	"expires_at",
+	"is_revoked",
+	"created_at",
+	"updated_at",
+	"last_used_at",
+	"user_agent",
+	"ip_address",
+}
+
+var _ TokenRepository = (*tokenRepository)(nil)
+
 type tokenRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -27,50 +44,42 @@ func NewTokenRepository(pool *pgxpool.Pool, logger *logrus.Logger) *tokenReposit
 	}
 }
 
-func (r *tokenRepository) GetRefreshTokenByUserID(ctx context.Context, userID string) (*entity.RefreshToken, error) {
+func (r *tokenRepository) GetByToken(ctx context.Context, token string) (*entity.RefreshToken, error) {
+	return r.getBy(ctx, "GetByToken", sq.Eq{"token": token})
+}
+
+func (r *tokenRepository) GetByID(ctx context.Context, id string) (*entity.RefreshToken, error) {
+	return r.getBy(ctx, "GetByID", sq.Eq{"id": id})
+}
+
+func (r *tokenRepository) getBy(ctx context.Context, method string, where sq.Eq) (*entity.RefreshToken, error) {
 	query, args, err := psql.
-		Select(
-			"user_id",
-			"token",
-			"expires_at",
-			"is_revoked",
-			"created_at",
-			"updated_at",
-		).
+		Select(tokenColumns...).
 		From("tokens").
-		Where(sq.Eq{"user_id": userID}).
+		Where(where).
 		ToSql()
 	if err != nil {
 		r.logger.WithFields(logrus.Fields{
-			"method":  "GetRefreshTokenByUserID",
-			"user_id": userID,
-			"error":   err,
+			"method": method,
+			"error":  err,
 		}).Error("failed to build SQL query")
 		return nil, appErrors.ErrInternal
 	}
 
 	var t entity.RefreshToken
-
-	row := r.pool.QueryRow(ctx, query, args...)
-	if err := row.Scan(
-		&t.UserID,
-		&t.Token,
-		&t.ExpiresAt,
-		&t.IsRevoked,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	); err != nil {
+	if err := scanToken(adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...), &t); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.logger.WithFields(logrus.Fields{
-				"method":  "GetRefreshTokenByUserID",
-				"user_id": userID,
+				"method": method,
 			}).Info("refresh token not found")
-			return nil, appErrors.ErrNotFound
+			return nil, appErrors.NewAppError("NOT_FOUND", "refresh token not found", appErrors.ErrNotFound)
 		}
 		r.logger.WithFields(logrus.Fields{
-			"method":  "GetRefreshTokenByUserID",
-			"user_id": userID,
-			"error":   err,
+			"method": method,
+			"error":  err,
 		}).Error("failed to scan row")
 		return nil, appErrors.ErrInternal
 	}
@@ -81,28 +90,26 @@ func (r *tokenRepository) GetRefreshTokenByUserID(ctx context.Context, userID st
 func (r *tokenRepository) UpsertRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
 	query, args, err := psql.
 		Insert("tokens").
-		Columns(
-			"user_id",
-			"token",
-			"expires_at",
-			"is_revoked",
-			"created_at",
-			"updated_at",
-		).
+		Columns(tokenColumns...).
 		Values(
+			token.ID,
 			token.UserID,
 			token.Token,
 			token.ExpiresAt,
 			token.IsRevoked,
 			token.CreatedAt,
 			token.UpdatedAt,
+			token.LastUsedAt,
+			token.UserAgent,
+			token.IPAddress,
 		).
 		Suffix(`
-			ON CONFLICT (user_id) DO UPDATE 
+			ON CONFLICT (id) DO UPDATE 
 			SET token = EXCLUDED.token,
 				expires_at = EXCLUDED.expires_at,
 				is_revoked = EXCLUDED.is_revoked,
-				updated_at = EXCLUDED.updated_at
+				updated_at = EXCLUDED.updated_at,
+				last_used_at = EXCLUDED.last_used_at
 		`).
 		ToSql()
 	if err != nil {
@@ -114,8 +121,11 @@ func (r *tokenRepository) UpsertRefreshToken(ctx context.Context, token *entity.
 		return appErrors.ErrInternal
 	}
 
-	_, err = r.pool.Exec(ctx, query, args...)
+	_, err = adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
 	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithFields(logrus.Fields{
 			"method":  "UpsertRefreshToken",
 			"user_id": token.UserID,
@@ -125,9 +135,202 @@ func (r *tokenRepository) UpsertRefreshToken(ctx context.Context, token *entity.
 	}
 
 	r.logger.WithFields(logrus.Fields{
-		"method":  "UpsertRefreshToken",
-		"user_id": token.UserID,
+		"method":     "UpsertRefreshToken",
+		"user_id":    token.UserID,
+		"session_id": token.ID,
 	}).Info("refresh token successfully upserted")
 
 	return nil
 }
+
+func (r *tokenRepository) ListSessionsByUserID(ctx context.Context, userID string) ([]entity.RefreshToken, error) {
+	query, args, err := psql.
+		Select(tokenColumns...).
+		From("tokens").
+		Where(sq.Eq{"user_id": userID, "is_revoked": false}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":  "ListSessionsByUserID",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to build SQL query")
+		return nil, appErrors.ErrInternal
+	}
+
+	rows, err := adapter.QuerierFrom(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "ListSessionsByUserID",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed to execute query")
+		return nil, appErrors.ErrInternal
+	}
+	defer rows.Close()
+
+	sessions := []entity.RefreshToken{}
+	for rows.Next() {
+		var t entity.RefreshToken
+		if err := scanToken(rows, &t); err != nil {
+			if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+				return nil, ctxErr
+			}
+			r.logger.WithFields(logrus.Fields{
+				"method":  "ListSessionsByUserID",
+				"user_id": userID,
+				"error":   err,
+			}).Error("failed to scan row")
+			return nil, appErrors.ErrInternal
+		}
+		sessions = append(sessions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "ListSessionsByUserID",
+			"user_id": userID,
+			"error":   err,
+		}).Error("failed after scanning rows")
+		return nil, appErrors.ErrInternal
+	}
+
+	return sessions, nil
+}
+
+func (r *tokenRepository) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	revoked, err := r.revoke(ctx, "RevokeSession", sq.Eq{"id": sessionID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		r.logger.WithFields(logrus.Fields{
+			"method":     "RevokeSession",
+			"user_id":    userID,
+			"session_id": sessionID,
+		}).Info("session not found")
+		return appErrors.NewAppError("NOT_FOUND", "session not found", appErrors.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *tokenRepository) RevokeToken(ctx context.Context, tokenID string) (bool, error) {
+	revoked, err := r.revoke(ctx, "RevokeToken", sq.Eq{"id": tokenID})
+	if err != nil {
+		return false, err
+	}
+
+	return revoked > 0, nil
+}
+
+func (r *tokenRepository) RevokeAllForUser(ctx context.Context, userID string) (int64, error) {
+	return r.revoke(ctx, "RevokeAllForUser", sq.Eq{"user_id": userID})
+}
+
+func (r *tokenRepository) RevokeOldestSessions(ctx context.Context, userID string, keep int) (int64, error) {
+	newest := sq.Select("id").
+		From("tokens").
+		Where(sq.Eq{"user_id": userID, "is_revoked": false}).
+		Where(sq.Gt{"expires_at": time.Now()}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(keep))
+
+	return r.revoke(ctx, "RevokeOldestSessions", sq.And{
+		sq.Eq{"user_id": userID},
+		sq.Expr("id NOT IN (?)", newest),
+	})
+}
+
+func (r *tokenRepository) TouchSession(ctx context.Context, sessionID string, usedAt time.Time) error {
+	query, args, err := psql.
+		Update("tokens").
+		Set("last_used_at", usedAt).
+		Where(sq.Eq{"id": sessionID}).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":     "TouchSession",
+			"session_id": sessionID,
+			"error":      err,
+		}).Error("failed to build SQL update query")
+		return appErrors.ErrInternal
+	}
+
+	if _, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":     "TouchSession",
+			"session_id": sessionID,
+			"error":      err,
+		}).Error("failed to execute update query")
+		return appErrors.ErrInternal
+	}
+
+	return nil
+}
+
+func (r *tokenRepository) revoke(ctx context.Context, method string, where sq.Sqlizer) (int64, error) {
+	query, args, err := psql.
+		Update("tokens").
+		Set("is_revoked", true).
+		Set("updated_at", time.Now()).
+		Where(where).
+		Where(sq.Eq{"is_revoked": false}).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method": method,
+			"where":  where,
+			"error":  err,
+		}).Error("failed to build SQL update query")
+		return 0, appErrors.ErrInternal
+	}
+
+	tag, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return 0, ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method": method,
+			"where":  where,
+			"error":  err,
+		}).Error("failed to execute update query")
+		return 0, appErrors.ErrInternal
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"method":  method,
+		"where":   where,
+		"revoked": tag.RowsAffected(),
+	}).Info("refresh tokens revoked")
+
+	return tag.RowsAffected(), nil
+}
+
+// scanToken scans a row selected with tokenColumns.
+func scanToken(row pgx.Row, t *entity.RefreshToken) error {
+	return row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Token,
+		&t.ExpiresAt,
+		&t.IsRevoked,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.LastUsedAt,
+		&t.UserAgent,
+		&t.IPAddress,
+	)
+}
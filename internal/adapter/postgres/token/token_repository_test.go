@@ -0,0 +1,26 @@
+package token
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetUnknownIsNotFound(t *testing.T) {
+	pool := pgtest.New(t)
+	pgtest.Seller(t, pool, "seller-1")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := NewTokenRepository(pool, logger)
+
+	pgtest.AssertLookupsNotFound(t, map[string]pgtest.Lookup{
+		"GetByToken": pgtest.Missing(repo.GetByToken, "missing"),
+		"GetByID":    pgtest.Missing(repo.GetByID, "missing"),
+		"RevokeSession": func(ctx context.Context) error {
+			return repo.RevokeSession(ctx, "seller-1", "missing")
+		},
+	})
+}
@@ -5,9 +5,20 @@ import (
 	"marketplace/internal/entity"
 )
 
+// UserRepository lookups skip soft deleted users. Exists does not, because
+// their email and username stay taken.
 type UserRepository interface {
 	Create(ctx context.Context, customer *entity.User) error
 	GetByID(ctx context.Context, userID string) (*entity.User, error)
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetByUsername(ctx context.Context, username string) (*entity.User, error)
+	// Exists reports whether a row with field = value exists. field is a
+	// column name and must never come from user input.
+	Exists(ctx context.Context, field, value string) (bool, error)
 	UpdateAuth(ctx context.Context, id string, username, email, password string) error
+	// MarkVerified records that the user confirmed their email.
+	MarkVerified(ctx context.Context, id string) error
 	Delete(ctx context.Context, id string) error
+	// SoftDelete marks the user deleted and keeps the row.
+	SoftDelete(ctx context.Context, id string) error
 }
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"marketplace/internal/entity"
 	appError "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -17,6 +19,8 @@ import (
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var _ UserRepository = (*userRepository)(nil)
+
 type userRepository struct {
 	pool   *pgxpool.Pool
 	logger *logrus.Logger
@@ -29,9 +33,13 @@ func NewUserRepository(pool *pgxpool.Pool, logger *logrus.Logger) *userRepositor
 	}
 }
 
+// Create joins the caller's transaction when ctx carries one.
 func (r *userRepository) Create(ctx context.Context, user *entity.User) (err error) {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := adapter.QuerierFrom(ctx, r.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to begin transaction")
 		return appError.NewAppError("TX_BEGIN_FAIL", "could not start DB transaction", err)
 	}
@@ -44,8 +52,8 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (err err
 
 	query, args, err := psql.
 		Insert("users").
-		Columns("id", "user_type", "username", "password_hash", "email", "created_at", "updated_at").
-		Values(user.ID, user.UserType, user.Username, user.PasswordHash, user.Email, user.CreatedAt, user.UpdatedAt).
+		Columns("id", "user_type", "username", "password_hash", "email", "created_at", "updated_at", "is_verified").
+		Values(user.ID, user.UserType, user.Username, user.PasswordHash, user.Email, user.CreatedAt, user.UpdatedAt, user.IsVerified).
 		ToSql()
 	if err != nil {
 		r.logger.WithError(err).Error("failed to build insert query for users")
@@ -54,6 +62,9 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (err err
 
 	res, err := tx.Exec(ctx, query, args...)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to execute insert query for users")
 		return appError.NewAppError("EXEC_ERROR", "could not execute insert query for users", err)
 	}
@@ -82,6 +93,9 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (err err
 
 	res2, err := tx.Exec(ctx, q2, a2...)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to execute subtype insert")
 		return appError.NewAppError("EXEC_ERROR", "could not execute subtype insert", err)
 	}
@@ -91,6 +105,9 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (err err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to commit transaction")
 		return appError.NewAppError("TX_COMMIT_FAIL", "could not commit transaction", err)
 	}
@@ -104,18 +121,30 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (err err
 }
 
 func (r *userRepository) GetByID(ctx context.Context, userID string) (*entity.User, error) {
+	return r.getByField(ctx, "id", userID)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.getByField(ctx, "email", email)
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.getByField(ctx, "username", username)
+}
+
+func (r *userRepository) getByField(ctx context.Context, field, value string) (*entity.User, error) {
 	query, args, err := psql.
-		Select("id", "user_type", "username", "password_hash", "email", "created_at", "updated_at").
+		Select("id", "user_type", "username", "password_hash", "email", "created_at", "updated_at", "is_verified").
 		From("users").
-		Where(sq.Eq{"id": userID}).
+		Where(sq.Eq{field: value, "deleted_at": nil}).
 		ToSql()
 	if err != nil {
-		r.logger.WithError(err).Error("failed to build select query for user by id")
-		return nil, appError.NewAppError("SQL_BUILD_ERROR", "could not build select query for user by id", err)
+		r.logger.WithError(err).Errorf("failed to build select query for user by %s", field)
+		return nil, appError.NewAppError("SQL_BUILD_ERROR", "could not build select query for user by "+field, err)
 	}
 
 	var u entity.User
-	err = r.pool.QueryRow(ctx, query, args...).Scan(
+	err = adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
 		&u.ID,
 		&u.UserType,
 		&u.Username,
@@ -123,19 +152,48 @@ func (r *userRepository) GetByID(ctx context.Context, userID string) (*entity.Us
 		&u.Email,
 		&u.CreatedAt,
 		&u.UpdatedAt,
+		&u.IsVerified,
 	)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
-			r.logger.WithField("user_id", userID).Warn("user not found by id")
+			r.logger.WithField(field, value).Warnf("user not found by %s", field)
 			return nil, appError.NewAppError("NOT_FOUND", "user not found", appError.ErrNotFound)
 		}
-		r.logger.WithError(err).Error("failed to execute select query for user by id")
-		return nil, appError.NewAppError("EXEC_ERROR", "could not execute select query for user by id", err)
+		r.logger.WithError(err).Errorf("failed to execute select query for user by %s", field)
+		return nil, appError.NewAppError("EXEC_ERROR", "could not execute select query for user by "+field, err)
 	}
 
 	return &u, nil
 }
 
+func (r *userRepository) Exists(ctx context.Context, field, value string) (bool, error) {
+	query, args, err := psql.
+		Select("1").
+		From("users").
+		Where(sq.Eq{field: value}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		r.logger.WithError(err).Errorf("failed to build exists query for user by %s", field)
+		return false, appError.NewAppError("SQL_BUILD_ERROR", "could not build exists query for user by "+field, err)
+	}
+
+	var exists bool
+	if err := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return false, ctxErr
+		}
+		r.logger.WithError(err).Errorf("failed to execute exists query for user by %s", field)
+		return false, appError.NewAppError("EXEC_ERROR", "could not execute exists query for user by "+field, err)
+	}
+
+	return exists, nil
+}
+
 func (r *userRepository) UpdateAuth(ctx context.Context, id string, username, email, password string) error {
 
 	query, args, err := psql.
@@ -150,8 +208,11 @@ func (r *userRepository) UpdateAuth(ctx context.Context, id string, username, em
 		return appError.NewAppError("SQL_BUILD_ERROR", "could not build update query", err)
 	}
 
-	res, err := r.pool.Exec(ctx, query, args...)
+	res, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to execute update query")
 		return appError.NewAppError("EXEC_ERROR", "could not execute update query", err)
 	}
@@ -165,9 +226,113 @@ func (r *userRepository) UpdateAuth(ctx context.Context, id string, username, em
 	return nil
 }
 
+// MarkVerified joins the caller's transaction when ctx carries one.
+func (r *userRepository) MarkVerified(ctx context.Context, id string) error {
+	query, args, err := psql.
+		Update("users").
+		Set("is_verified", true).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		r.logger.WithError(err).Error("failed to build mark verified query")
+		return appError.NewAppError("SQL_BUILD_ERROR", "could not build mark verified query", err)
+	}
+
+	res, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).Error("failed to execute mark verified query")
+		return appError.NewAppError("EXEC_ERROR", "could not execute mark verified query", err)
+	}
+	if res.RowsAffected() == 0 {
+		return appError.NewAppError("NOT_FOUND", "user not found", appError.ErrNotFound)
+	}
+
+	r.logger.WithField("user_id", id).Info("user email verified")
+	return nil
+}
+
+// SoftDelete also releases the customer's phone key, as a deleted user
+// no longer holds its phone.
+func (r *userRepository) SoftDelete(ctx context.Context, id string) (err error) {
+	tx, err := adapter.QuerierFrom(ctx, r.pool).Begin(ctx)
+	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).Error("failed to begin soft delete transaction")
+		return appError.NewAppError("TX_BEGIN_FAIL", "could not begin soft delete transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			r.logger.WithError(err).Warn("soft delete transaction rolled back")
+		}
+	}()
+
+	now := time.Now()
+	query, args, err := psql.
+		Update("users").
+		Set("deleted_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		r.logger.WithError(err).Error("failed to build soft delete query")
+		return appError.NewAppError("SQL_BUILD_ERROR", "could not build soft delete query", err)
+	}
+
+	res, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).Error("failed to execute soft delete query")
+		return appError.NewAppError("EXEC_ERROR", "could not execute soft delete query", err)
+	}
+	if res.RowsAffected() == 0 {
+		r.logger.Warn("soft delete affected 0 rows")
+		return appError.NewAppError("NOT_DELETED", "soft delete returned 0 affected rows", appError.ErrNotFound)
+	}
+
+	query, args, err = psql.
+		Update("customers").
+		Set("phone_key", nil).
+		Where(sq.Eq{"user_id": id}).
+		ToSql()
+	if err != nil {
+		r.logger.WithError(err).Error("failed to build phone release query")
+		return appError.NewAppError("SQL_BUILD_ERROR", "could not build phone release query", err)
+	}
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).Error("failed to execute phone release query")
+		return appError.NewAppError("EXEC_ERROR", "could not execute phone release query", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithError(err).Error("failed to commit soft delete transaction")
+		return appError.NewAppError("TX_COMMIT_FAIL", "could not commit soft delete transaction", err)
+	}
+
+	r.logger.WithField("user_id", id).Info("user soft deleted successfully")
+	return nil
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) (err error) {
-	tx, err := r.pool.Begin(ctx)
+	tx, err := adapter.QuerierFrom(ctx, r.pool).Begin(ctx)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to begin delete transaction")
 		return appError.NewAppError("TX_BEGIN_FAIL", "could not begin delete transaction", err)
 	}
@@ -186,6 +351,9 @@ func (r *userRepository) Delete(ctx context.Context, id string) (err error) {
 
 	res, err := tx.Exec(ctx, query, args...)
 	if err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to execute delete query")
 		return appError.NewAppError("EXEC_ERROR", "could not execute delete query", err)
 	}
@@ -195,6 +363,9 @@ func (r *userRepository) Delete(ctx context.Context, id string) (err error) {
 	}
 
 	if err = tx.Commit(ctx); err != nil {
+		if ctxErr := appError.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
 		r.logger.WithError(err).Error("failed to commit delete transaction")
 		return appError.NewAppError("TX_COMMIT_FAIL", "could not commit delete transaction", err)
 	}
@@ -0,0 +1,75 @@
+package user
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestRepository(t *testing.T) *userRepository {
+	t.Helper()
+	pool := pgtest.New(t)
+	pgtest.Seller(t, pool, "seller-1")
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewUserRepository(pool, logger)
+}
+
+func TestExists(t *testing.T) {
+	repo := newTestRepository(t)
+
+	tests := []struct {
+		field, value string
+		want         bool
+	}{
+		{"email", "seller-1@example.com", true},
+		{"username", "seller-1", true},
+		{"email", "nobody@example.com", false},
+		{"username", "nobody", false},
+	}
+
+	for _, tt := range tests {
+		exists, err := repo.Exists(context.Background(), tt.field, tt.value)
+		if err != nil {
+			t.Fatalf("Exists(%s, %s): %v", tt.field, tt.value, err)
+		}
+		if exists != tt.want {
+			t.Errorf("Exists(%s, %s) = %v, want %v", tt.field, tt.value, exists, tt.want)
+		}
+	}
+}
+
+func TestGetUnknownIsNotFound(t *testing.T) {
+	repo := newTestRepository(t)
+
+	pgtest.AssertLookupsNotFound(t, map[string]pgtest.Lookup{
+		"GetByID":       pgtest.Missing(repo.GetByID, "missing"),
+		"GetByEmail":    pgtest.Missing(repo.GetByEmail, "nobody@example.com"),
+		"GetByUsername": pgtest.Missing(repo.GetByUsername, "nobody"),
+	})
+}
+
+func TestSoftDeleteReleasesPhoneKey(t *testing.T) {
+	repo := newTestRepository(t)
+	pgtest.Customer(t, repo.pool, "customer-1")
+	ctx := context.Background()
+	if _, err := repo.pool.Exec(ctx, `UPDATE customers SET phone = '+15550100', phone_key = '+15550100' WHERE user_id = 'customer-1'`); err != nil {
+		t.Fatalf("seed phone: %v", err)
+	}
+
+	if err := repo.SoftDelete(ctx, "customer-1"); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+
+	var key *string
+	if err := repo.pool.QueryRow(ctx, `SELECT phone_key FROM customers WHERE user_id = 'customer-1'`).Scan(&key); err != nil {
+		t.Fatalf("read phone key: %v", err)
+	}
+	if key != nil {
+		t.Errorf("phone_key = %q after soft delete, want null", *key)
+	}
+}
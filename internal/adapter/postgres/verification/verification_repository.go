@@ -0,0 +1,16 @@
+package verification
+
+import (
+	"context"
+	"marketplace/internal/entity"
+	"time"
+)
+
+// VerificationRepository stores email verification tokens by hash. Both
+// methods join the caller's transaction when ctx carries one.
+type VerificationRepository interface {
+	Create(ctx context.Context, v *entity.EmailVerification) error
+	// Consume marks an unused, unexpired token as used and returns its
+	// user. Unknown, used and expired tokens all fail with INVALID_INPUT.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (string, error)
+}
@@ -0,0 +1,95 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"marketplace/internal/entity"
+	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+var _ VerificationRepository = (*verificationRepository)(nil)
+
+type verificationRepository struct {
+	pool   *pgxpool.Pool
+	logger *logrus.Logger
+}
+
+func NewVerificationRepository(pool *pgxpool.Pool, logger *logrus.Logger) *verificationRepository {
+	return &verificationRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *verificationRepository) Create(ctx context.Context, v *entity.EmailVerification) error {
+	query, args, err := psql.
+		Insert("email_verifications").
+		Columns("token_hash", "user_id", "expires_at", "created_at").
+		Values(v.TokenHash, v.UserID, v.ExpiresAt, v.CreatedAt).
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Create",
+			"user_id": v.UserID,
+			"error":   err,
+		}).Error("failed to build SQL insert query")
+		return appErrors.NewAppError("SQL_BUILD_ERROR", "could not build verification insert query", err)
+	}
+
+	if _, err := adapter.QuerierFrom(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return ctxErr
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method":  "Create",
+			"user_id": v.UserID,
+			"error":   err,
+		}).Error("failed to execute insert query")
+		return appErrors.NewAppError("EXEC_ERROR", "could not store verification token", err)
+	}
+
+	return nil
+}
+
+func (r *verificationRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	query, args, err := psql.
+		Update("email_verifications").
+		Set("used_at", now).
+		Where(sq.Eq{"token_hash": tokenHash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"method": "Consume",
+			"error":  err,
+		}).Error("failed to build SQL update query")
+		return "", appErrors.NewAppError("SQL_BUILD_ERROR", "could not build verification update query", err)
+	}
+
+	var userID string
+	if err := adapter.QuerierFrom(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&userID); err != nil {
+		if ctxErr := appErrors.FromContext(err); ctxErr != nil {
+			return "", ctxErr
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", appErrors.NewAppError("INVALID_INPUT", "invalid or expired verification token", nil)
+		}
+		r.logger.WithFields(logrus.Fields{
+			"method": "Consume",
+			"error":  err,
+		}).Error("failed to execute update query")
+		return "", appErrors.NewAppError("EXEC_ERROR", "could not consume verification token", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,55 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"marketplace/internal/entity"
+	appErrors "marketplace/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestConsume(t *testing.T) {
+	pool := pgtest.New(t)
+	pgtest.Seller(t, pool, "seller-1")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := NewVerificationRepository(pool, logger)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for hash, expiresAt := range map[string]time.Time{
+		"fresh":   now.Add(time.Hour),
+		"expired": now.Add(-time.Minute),
+	} {
+		if err := repo.Create(ctx, &entity.EmailVerification{TokenHash: hash, UserID: "seller-1", ExpiresAt: expiresAt, CreatedAt: now}); err != nil {
+			t.Fatalf("Create %s: %v", hash, err)
+		}
+	}
+
+	userID, err := repo.Consume(ctx, "fresh", now)
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if userID != "seller-1" {
+		t.Errorf("user = %q, want seller-1", userID)
+	}
+
+	for name, hash := range map[string]string{
+		"used":    "fresh",
+		"expired": "expired",
+		"unknown": "missing",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := repo.Consume(ctx, hash, now)
+			var appErr *appErrors.AppError
+			if !errors.As(err, &appErr) || appErr.Code() != "INVALID_INPUT" {
+				t.Errorf("error = %v, want INVALID_INPUT", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type SellerAPIToken struct {
+	SellerID  string    `json:"seller_id" db:"seller_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	IsRevoked bool      `json:"is_revoked" db:"is_revoked"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
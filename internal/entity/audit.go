@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	AuditRegister       = "register"
+	AuditLogin          = "login"
+	AuditLogout         = "logout"
+	AuditPasswordChange = "password_change"
+	AuditAuthChange     = "auth_change"
+	AuditProfileChange  = "profile_change"
+	AuditDelete         = "delete"
+)
+
+// AuditEvent records a security-relevant action of a user. UserID has no
+// foreign key so the trail survives account deletion.
+type AuditEvent struct {
+	ID        string         `json:"id" db:"id"`
+	UserID    string         `json:"user_id" db:"user_id"`
+	Event     string         `json:"event" db:"event"`
+	IPAddress sql.NullString `json:"ip_address" db:"ip_address"`
+	UserAgent sql.NullString `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// EmailVerification is a one-time token sent to a new user's email. Only
+// the SHA-256 of the token is stored.
+type EmailVerification struct {
+	TokenHash string       `json:"-" db:"token_hash"`
+	UserID    string       `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time    `json:"expires_at" db:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at" db:"used_at"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
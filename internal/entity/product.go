@@ -2,16 +2,40 @@ package entity
 
 import "time"
 
+// Product moderation states. Only approved products are publicly listed.
+const (
+	ModerationApproved = "approved"
+	ModerationPending  = "pending"
+	ModerationRejected = "rejected"
+)
+
 type Product struct {
-	ID          string    `db:"id" json:"id"`
-	SellerID    string    `db:"seller_id" json:"seller_id"`
-	Title       string    `db:"title" json:"title"`
-	Description string    `db:"description" json:"description"`
-	Price       float64   `db:"price" json:"price"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	CategoryID  string    `db:"category_id" json:"category_id"`
-	IsActive    bool      `db:"is_active" json:"is_active"`
+	ID              string    `db:"id" json:"id"`
+	SellerID        string    `db:"seller_id" json:"seller_id"`
+	Title           string    `db:"title" json:"title"`
+	TitleNormalized string    `db:"title_normalized" json:"-"`
+	Description     string    `db:"description" json:"description"`
+	Price           float64   `db:"price" json:"price"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	CategoryID      string    `db:"category_id" json:"category_id"`
+	IsActive        bool      `db:"is_active" json:"is_active"`
+	Stock           int       `db:"stock" json:"stock"`
+	// TitleKey is TitleNormalized qualified by the title uniqueness scope.
+	// A unique index on it backs the duplicate check.
+	TitleKey string `db:"title_key" json:"-"`
+	// Version is bumped on every update and used for optimistic locking.
+	Version          int    `db:"version" json:"version"`
+	Featured         bool   `db:"featured" json:"featured"`
+	ModerationStatus string `db:"moderation_status" json:"moderation_status"`
+	// ModerationReason explains a rejection; it is empty otherwise.
+	ModerationReason string `db:"moderation_reason" json:"moderation_reason"`
+	// ViewCount is how often the product page was opened. Views are
+	// flushed in batches, so it lags slightly behind.
+	ViewCount int64 `db:"view_count" json:"view_count"`
+	// PrimaryImageURL is the URL of the product's primary image, if any.
+	// It is read-only and never written back.
+	PrimaryImageURL *string `db:"primary_image_url" json:"primary_image_url,omitempty"`
 }
 
 type ProductImage struct {
@@ -19,11 +43,14 @@ type ProductImage struct {
 	ProductID string    `db:"product_id" json:"product_id"`
 	URL       string    `db:"url" json:"url"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// IsPrimary marks the image shown for the product in listings.
+	IsPrimary bool `db:"is_primary" json:"is_primary"`
 }
 
 type Category struct {
 	ID        string    `db:"id" json:"id"`
 	Name      string    `db:"name" json:"name"`
+	ParentID  *string   `db:"parent_id" json:"parent_id"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -1,12 +1,19 @@
 package entity
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type RefreshToken struct {
-	UserID    string    `json:"user_id" db:"user_id"`
-	Token     string    `json:"token" db:"token"`
-	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
-	IsRevoked bool      `json:"is_revoked,omitempty" db:"is_revoked"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         string         `json:"id" db:"id"`
+	UserID     string         `json:"user_id" db:"user_id"`
+	Token      string         `json:"token" db:"token"`
+	ExpiresAt  time.Time      `json:"expires_at" db:"expires_at"`
+	IsRevoked  bool           `json:"is_revoked,omitempty" db:"is_revoked"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+	LastUsedAt sql.NullTime   `json:"last_used_at" db:"last_used_at"`
+	UserAgent  sql.NullString `json:"user_agent" db:"user_agent"`
+	IPAddress  sql.NullString `json:"ip_address" db:"ip_address"`
 }
@@ -13,6 +13,8 @@ type User struct {
 	Email        string    `db:"email" json:"email,omitempty"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at,omitempty"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at,omitempty"`
+	// IsVerified is set once the user confirmed their email address.
+	IsVerified bool `db:"is_verified" json:"is_verified"`
 }
 
 type CustomerProfile struct {
@@ -20,8 +22,16 @@ type CustomerProfile struct {
 	FirstName sql.NullString `json:"first_name" db:"first_name"`
 	LastName  sql.NullString `json:"last_name" db:"last_name"`
 	Phone     sql.NullString `json:"phone" db:"phone"`
+	// PhoneKey is Phone while phones must be unique and null otherwise.
+	PhoneKey  sql.NullString `json:"-" db:"phone_key"`
 	DateBirth sql.NullTime   `json:"date_birth" db:"date_birth"`
 	Address   sql.NullString `json:"address" db:"address"`
+	// Shipping address. Address above is the older free-text form and is
+	// kept in sync for clients that still read it.
+	AddressLine1      sql.NullString `json:"address_line1" db:"address_line1"`
+	AddressCity       sql.NullString `json:"address_city" db:"address_city"`
+	AddressPostalCode sql.NullString `json:"address_postal_code" db:"address_postal_code"`
+	AddressCountry    sql.NullString `json:"address_country" db:"address_country"`
 }
 
 type SellerProfile struct {
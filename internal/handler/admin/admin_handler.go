@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"marketplace/internal/handler/response"
+	usecase "marketplace/internal/usecase/admin"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/dto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultAuditLimit = 50
+
+type AdminHandler struct {
+	usecase   usecase.AdminUsecase
+	responder *response.Responder
+	binder    *response.Binder
+}
+
+func NewAdminHandler(usecase usecase.AdminUsecase, responder *response.Responder, binder *response.Binder) *AdminHandler {
+	return &AdminHandler{
+		usecase:   usecase,
+		responder: responder,
+		binder:    binder,
+	}
+}
+
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	adminID := authctx.FromContext(c.Request.Context()).ID
+	userID := c.Param("userID")
+
+	resp, err := h.usecase.Impersonate(c.Request.Context(), adminID, userID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusCreated, resp)
+}
+
+// RevokeSessions force-logs a user out of every device.
+func (h *AdminHandler) RevokeSessions(c *gin.Context) {
+	adminID := authctx.FromContext(c.Request.Context()).ID
+	userID := c.Param("userID")
+
+	revoked, err := h.usecase.RevokeSessions(c.Request.Context(), adminID, userID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, dto.RevokeSessionsResponse{Revoked: revoked})
+}
+
+// ListAudit returns audit events, optionally filtered by user_id and event.
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	var filter dto.AuditFilter
+	if err := h.binder.BindQuery(c, &filter); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	limit, offset, err := h.binder.Page(c, defaultAuditLimit)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	events, err := h.usecase.ListAudit(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, events, dto.NewPageMeta(limit, offset))
+}
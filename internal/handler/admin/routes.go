@@ -0,0 +1,20 @@
+package admin
+
+import (
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/handler/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func RegisterAdminRoutes(rg *gin.RouterGroup, h *AdminHandler, jwtManager jwt.JWTManager, log *logrus.Logger) {
+	admin := rg.Group("/admin")
+	admin.Use(middleware.AccessTokenMiddleware(jwtManager, log))
+	admin.Use(middleware.RequireRole(middleware.UserTypeAdmin, log))
+	{
+		admin.POST("/impersonate/:userID", h.Impersonate)
+		admin.POST("/users/:userID/revoke-sessions", h.RevokeSessions)
+		admin.GET("/audit", h.ListAudit)
+	}
+}
@@ -4,32 +4,34 @@ import (
 	"errors"
 	"marketplace/internal/handler/response"
 	usecase "marketplace/internal/usecase/auth"
+	"marketplace/pkg/authctx"
 	"marketplace/pkg/dto"
 	appErrors "marketplace/pkg/errors"
 	"marketplace/pkg/validator"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 type AuthHandler struct {
 	authUsecase usecase.AuthUsecase
 	responder   *response.Responder
+	binder      *response.Binder
 	validate    validator.Validator
 }
 
-func NewAuthHandler(authUsecase usecase.AuthUsecase, logger *logrus.Logger) *AuthHandler {
+func NewAuthHandler(authUsecase usecase.AuthUsecase, responder *response.Responder, binder *response.Binder) *AuthHandler {
 	return &AuthHandler{
 		authUsecase: authUsecase,
-		responder:   response.New(logger),
+		responder:   responder,
+		binder:      binder,
 		validate:    validator.NewValidator(),
 	}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := h.binder.BindJSON(c, &req); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -49,7 +51,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := h.binder.BindJSON(c, &req); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -67,9 +69,48 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	h.responder.Success(c, http.StatusOK, resp)
 }
 
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appErrors.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	resp, err := h.authUsecase.Refresh(c.Request.Context(), req)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, resp)
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appErrors.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	if err := h.authUsecase.VerifyEmail(c.Request.Context(), req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
 func (h *AuthHandler) UpdateAuth(c *gin.Context) {
 	var req dto.UpdateAuthRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := h.binder.BindJSON(c, &req); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -78,7 +119,7 @@ func (h *AuthHandler) UpdateAuth(c *gin.Context) {
 		return
 	}
 
-	userID := c.GetString("userID")
+	userID := authctx.FromContext(c.Request.Context()).ID
 	refreshToken := req.RefreshToken
 
 	if refreshToken == "" {
@@ -94,14 +135,27 @@ func (h *AuthHandler) UpdateAuth(c *gin.Context) {
 	h.responder.NoContent(c)
 }
 
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	userID := authctx.FromContext(c.Request.Context()).ID
+	userType := authctx.FromContext(c.Request.Context()).Type
+
+	profile, err := h.authUsecase.GetProfile(c.Request.Context(), userID, userType)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, profile)
+}
+
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
-	userID := c.GetString("userID")
-	userType := c.GetString("userType")
+	userID := authctx.FromContext(c.Request.Context()).ID
+	userType := authctx.FromContext(c.Request.Context()).Type
 
 	switch userType {
 	case "customer":
 		var req dto.CustomerProfileRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
+		if err := h.binder.BindJSON(c, &req); err != nil {
 			h.responder.Error(c, err)
 			return
 		}
@@ -116,7 +170,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 	case "seller":
 		var req dto.SellerProfileRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
+		if err := h.binder.BindJSON(c, &req); err != nil {
 			h.responder.Error(c, err)
 			return
 		}
@@ -137,8 +191,27 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	h.responder.NoContent(c)
 }
 
+func (h *AuthHandler) UpdateShippingAddress(c *gin.Context) {
+	var req dto.ShippingAddress
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appErrors.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	if err := h.authUsecase.UpdateShippingAddress(c.Request.Context(), authctx.FromContext(c.Request.Context()).ID, req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
 func (h *AuthHandler) DeleteUser(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := authctx.FromContext(c.Request.Context()).ID
 
 	if err := h.authUsecase.DeleteUser(c.Request.Context(), userID); err != nil {
 		h.responder.Error(c, err)
@@ -147,3 +220,40 @@ func (h *AuthHandler) DeleteUser(c *gin.Context) {
 
 	h.responder.NoContent(c)
 }
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := authctx.FromContext(c.Request.Context()).ID
+
+	sessions, err := h.authUsecase.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, sessions)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := authctx.FromContext(c.Request.Context()).ID
+	sessionID := c.Param("id")
+
+	if err := h.authUsecase.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+func (h *AuthHandler) SessionStatus(c *gin.Context) {
+	userID := authctx.FromContext(c.Request.Context()).ID
+	userType := authctx.FromContext(c.Request.Context()).Type
+
+	status, err := h.authUsecase.SessionStatus(c.Request.Context(), userID, userType, c.Param("id"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, status)
+}
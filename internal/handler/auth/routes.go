@@ -13,9 +13,21 @@ func RegisterAuthRoutes(rg *gin.RouterGroup, h *AuthHandler, jwtManager jwt.JWTM
 
 	auth.POST("/register", h.Register)
 	auth.POST("/login", h.Login)
+	auth.POST("/refresh", h.Refresh)
+	auth.POST("/verify-email", h.VerifyEmail)
 
 	auth.PUT("/update-auth", middleware.AccessTokenMiddleware(jwtManager, log), h.UpdateAuth)
 
+	auth.GET("/profile", middleware.AccessTokenMiddleware(jwtManager, log), h.GetProfile)
 	auth.PUT("/update-profile", middleware.AccessTokenMiddleware(jwtManager, log), h.UpdateProfile)
+	auth.PUT("/profile/address",
+		middleware.AccessTokenMiddleware(jwtManager, log),
+		middleware.RequireRole(middleware.UserTypeCustomer, log),
+		h.UpdateShippingAddress,
+	)
 	auth.DELETE("/delete", middleware.AccessTokenMiddleware(jwtManager, log), h.DeleteUser)
+
+	auth.GET("/sessions", middleware.AccessTokenMiddleware(jwtManager, log), h.ListSessions)
+	auth.DELETE("/sessions/:id", middleware.AccessTokenMiddleware(jwtManager, log), h.RevokeSession)
+	auth.GET("/sessions/:id/status", middleware.AccessTokenMiddleware(jwtManager, log), h.SessionStatus)
 }
@@ -0,0 +1,141 @@
+package category
+
+import (
+	"marketplace/internal/handler/response"
+	usecase "marketplace/internal/usecase/category"
+	"marketplace/pkg/dto"
+	appError "marketplace/pkg/errors"
+	"marketplace/pkg/validator"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type categoryHandler struct {
+	usecase   usecase.CategoryUsecase
+	validate  validator.Validator
+	responder *response.Responder
+	binder    *response.Binder
+}
+
+func NewCategoryHandler(usecase usecase.CategoryUsecase, responder *response.Responder, binder *response.Binder) *categoryHandler {
+	return &categoryHandler{
+		usecase:   usecase,
+		responder: responder,
+		binder:    binder,
+		validate:  validator.NewValidator(),
+	}
+}
+
+func (h *categoryHandler) Create(c *gin.Context) {
+	var req dto.CategoryDTO
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	resp, err := h.usecase.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusCreated, resp)
+}
+
+func (h *categoryHandler) GetByID(c *gin.Context) {
+	category, err := h.usecase.GetByID(c.Request.Context(), c.Param("categoryID"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, category)
+}
+
+// Update renames the category. The id comes from the path; any id in the
+// body is ignored.
+func (h *categoryHandler) Update(c *gin.Context) {
+	var req dto.CategoryDTO
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	req.CategoryID = c.Param("categoryID")
+
+	resp, err := h.usecase.Update(c.Request.Context(), &req)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, resp)
+}
+
+func (h *categoryHandler) Delete(c *gin.Context) {
+	if err := h.usecase.Delete(c.Request.Context(), c.Param("categoryID")); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+func (h *categoryHandler) List(c *gin.Context) {
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	categories, err := h.usecase.List(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, categories, dto.NewPageMeta(limit, offset))
+}
+
+func (h *categoryHandler) Children(c *gin.Context) {
+	children, err := h.usecase.Children(c.Request.Context(), c.Param("categoryID"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, children)
+}
+
+func (h *categoryHandler) Tree(c *gin.Context) {
+	tree, err := h.usecase.Tree(c.Request.Context())
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, tree)
+}
+
+func (h *categoryHandler) Patch(c *gin.Context) {
+	var req dto.PatchCategoryRequest
+	categoryID := c.Param("categoryID")
+
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appError.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	resp, err := h.usecase.Patch(c.Request.Context(), categoryID, &req)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, resp)
+}
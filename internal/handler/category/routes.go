@@ -0,0 +1,29 @@
+package category
+
+import (
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/handler/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func RegisterCategoryRoutes(rg *gin.RouterGroup, h *categoryHandler, jwtManager jwt.JWTManager, log *logrus.Logger) {
+	publicGroup := rg.Group("/")
+	{
+		publicGroup.GET("/categories", h.List)
+		publicGroup.GET("/categories/tree", h.Tree)
+		publicGroup.GET("/categories/:categoryID", h.GetByID)
+		publicGroup.GET("/categories/:categoryID/children", h.Children)
+	}
+
+	adminGroup := rg.Group("/")
+	adminGroup.Use(middleware.AccessTokenMiddleware(jwtManager, log))
+	adminGroup.Use(middleware.RequireRole(middleware.UserTypeAdmin, log))
+	{
+		adminGroup.POST("/categories", h.Create)
+		adminGroup.PUT("/categories/:categoryID", h.Update)
+		adminGroup.PATCH("/categories/:categoryID", h.Patch)
+		adminGroup.DELETE("/categories/:categoryID", h.Delete)
+	}
+}
@@ -0,0 +1,118 @@
+package images
+
+import (
+	"marketplace/internal/handler/response"
+	usecase "marketplace/internal/usecase/images"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/dto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultImagesLimit = 20
+
+type imageHandler struct {
+	usecase   usecase.ImageUsecase
+	responder *response.Responder
+	binder    *response.Binder
+}
+
+func NewImageHandler(usecase usecase.ImageUsecase, responder *response.Responder, binder *response.Binder) *imageHandler {
+	return &imageHandler{
+		usecase:   usecase,
+		responder: responder,
+		binder:    binder,
+	}
+}
+
+// Create adds an image to the product in the path. A product_id in the body
+// is ignored.
+func (h *imageHandler) Create(c *gin.Context) {
+	var req dto.ImageDTO
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	req.ProductID = c.Param("productID")
+
+	image, err := h.usecase.Create(c.Request.Context(), &req, authctx.FromContext(c.Request.Context()).ID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusCreated, image)
+}
+
+func (h *imageHandler) List(c *gin.Context) {
+	productID := c.Param("productID")
+	limit, offset, err := h.binder.Page(c, defaultImagesLimit)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	images, err := h.usecase.ListByProductID(c.Request.Context(), productID, limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	total, err := h.usecase.CountByProductID(c.Request.Context(), productID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	meta := dto.NewPageMeta(limit, offset)
+	meta.Total = &total
+	h.responder.SuccessWithMeta(c, http.StatusOK, images, meta)
+}
+
+func (h *imageHandler) Delete(c *gin.Context) {
+	if err := h.usecase.Delete(c.Request.Context(), c.Param("imageID"), authctx.FromContext(c.Request.Context()).ID); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+func (h *imageHandler) SetPrimary(c *gin.Context) {
+	if err := h.usecase.SetPrimary(c.Request.Context(), c.Param("imageID"), authctx.FromContext(c.Request.Context()).ID); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+func (h *imageHandler) DeleteAll(c *gin.Context) {
+	productID := c.Param("productID")
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	deleted, err := h.usecase.DeleteAllForProduct(c.Request.Context(), productID, sellerID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, dto.DeleteImagesResponse{Deleted: deleted})
+}
+
+func (h *imageHandler) BulkDelete(c *gin.Context) {
+	var req dto.BulkDeleteImagesRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	result, err := h.usecase.BulkDelete(c.Request.Context(), req, authctx.FromContext(c.Request.Context()).ID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, result)
+}
@@ -0,0 +1,28 @@
+package images
+
+import (
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/handler/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func RegisterImageRoutes(rg *gin.RouterGroup, h *imageHandler, jwtManager jwt.JWTManager, apiTokens middleware.APITokenAuthenticator, log *logrus.Logger) {
+	readGroup := rg.Group("/")
+	readGroup.Use(middleware.AccessOrAPITokenMiddleware(jwtManager, apiTokens, log))
+	{
+		readGroup.GET("/products/:productID/images", h.List)
+	}
+
+	sellerGroup := rg.Group("/")
+	sellerGroup.Use(middleware.AccessTokenMiddleware(jwtManager, log))
+	sellerGroup.Use(middleware.RequireRole(middleware.UserTypeSeller, log))
+	{
+		sellerGroup.POST("/products/:productID/images", h.Create)
+		sellerGroup.DELETE("/products/:productID/images", h.DeleteAll)
+		sellerGroup.DELETE("/images", h.BulkDelete)
+		sellerGroup.DELETE("/images/:imageID", h.Delete)
+		sellerGroup.PUT("/images/:imageID/primary", h.SetPrimary)
+	}
+}
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"marketplace/internal/adapter/jwt"
-	"marketplace/pkg/dto"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/clientinfo"
+	"marketplace/pkg/config"
 	"net/http"
 	"strings"
 
@@ -15,10 +18,27 @@ import (
 const (
 	UserTypeSeller   = "seller"
 	UserTypeCustomer = "customer"
-	ContextUserID    = "userID"
-	ContextUserType  = "userType"
+	UserTypeAdmin    = "admin"
+	HeaderAPIToken   = "X-API-Token"
 )
 
+type APITokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// ClientInfoMiddleware stores the caller's User-Agent and IP in the request
+// context so they can be recorded with issued tokens.
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := clientinfo.WithInfo(c.Request.Context(), clientinfo.Info{
+			UserAgent: c.Request.UserAgent(),
+			IP:        c.ClientIP(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func AccessTokenMiddleware(jwtManager jwt.JWTManager, logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -36,7 +56,7 @@ func AccessTokenMiddleware(jwtManager jwt.JWTManager, logger *logrus.Logger) gin
 				return nil, fmt.Errorf("unexpected signing method")
 			}
 			return []byte(jwtManager.Secret()), nil
-		})
+		}, jwtManager.ParserOptions()...)
 		if err != nil || !parsedToken.Valid {
 			logger.WithFields(map[string]interface{}{
 				"token": tokenString,
@@ -73,83 +93,70 @@ func AccessTokenMiddleware(jwtManager jwt.JWTManager, logger *logrus.Logger) gin
 			return
 		}
 
-		logger.WithFields(map[string]interface{}{
+		user := authctx.User{ID: userID, Type: userType}
+		fields := map[string]interface{}{
 			"user_id":   userID,
 			"user_type": userType,
-		}).Info("AccessTokenMiddleware: token validated successfully")
+		}
+		if impersonatorID, ok := claims["imp"].(string); ok && impersonatorID != "" {
+			fields["impersonator_id"] = impersonatorID
+			user.ImpersonatorID = impersonatorID
+		}
+		logger.WithFields(fields).Info("AccessTokenMiddleware: token validated successfully")
 
-		c.Set("userID", userID)
-		c.Set("userType", userType)
+		c.Request = c.Request.WithContext(authctx.WithUser(c.Request.Context(), user))
 		c.Next()
 	}
 }
 
-func RefreshTokenMiddleware(jwtManager jwt.JWTManager, logger *logrus.Logger) gin.HandlerFunc {
+// AccessOrAPITokenMiddleware authenticates a seller by the API token in the
+// X-API-Token header and falls back to the bearer access token otherwise.
+func AccessOrAPITokenMiddleware(jwtManager jwt.JWTManager, apiTokens APITokenAuthenticator, logger *logrus.Logger) gin.HandlerFunc {
+	accessTokenMiddleware := AccessTokenMiddleware(jwtManager, logger)
+
 	return func(c *gin.Context) {
-		var req dto.RefreshTokenRequest
-		if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
-			logger.Warn("RefreshTokenMiddleware: invalid refresh token payload")
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid refresh token payload"})
+		apiToken := c.GetHeader(HeaderAPIToken)
+		if apiToken == "" {
+			accessTokenMiddleware(c)
 			return
 		}
 
-		parsedToken, err := jwtlib.Parse(req.RefreshToken, func(t *jwtlib.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwtlib.SigningMethodHMAC); !ok {
-				logger.Errorf("RefreshTokenMiddleware: unexpected signing method: %v", t.Header["alg"])
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(jwtManager.Secret()), nil
-		})
-		if err != nil || !parsedToken.Valid {
+		sellerID, err := apiTokens.Authenticate(c.Request.Context(), apiToken)
+		if err != nil {
 			logger.WithFields(map[string]interface{}{
-				"token": req.RefreshToken,
 				"error": err,
-			}).Error("RefreshTokenMiddleware: failed to parse or validate refresh token")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			}).Warn("AccessOrAPITokenMiddleware: api token authentication failed")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api token"})
 			return
 		}
 
-		claims, ok := parsedToken.Claims.(jwtlib.MapClaims)
-		if !ok {
-			logger.Error("RefreshTokenMiddleware: failed to cast token claims")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
-			return
-		}
+		logger.WithFields(map[string]interface{}{
+			"user_id": sellerID,
+		}).Info("AccessOrAPITokenMiddleware: api token validated successfully")
 
-		userID, ok := claims["user_id"].(string)
-		userType, ok2 := claims["user_type"].(string)
-		if !ok || !ok2 {
-			logger.WithFields(map[string]interface{}{
-				"user_id":   claims["user_id"],
-				"user_type": claims["user_type"],
-			}).Warn("RefreshTokenMiddleware: missing claims")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing claims"})
-			return
-		}
+		c.Request = c.Request.WithContext(authctx.WithUser(c.Request.Context(), authctx.User{ID: sellerID, Type: UserTypeSeller}))
+		c.Next()
+	}
+}
 
-		if err := jwtManager.ValidateRefreshToken(c.Request.Context(), req.RefreshToken); err != nil {
-			logger.WithFields(map[string]interface{}{
-				"user_id": userID,
-				"error":   err,
-			}).Error("RefreshTokenMiddleware: token validation failed")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
+// RequireFeature serves the route only when the feature is enabled and
+// answers 404 otherwise, as if the route did not exist. The flag is read once
+// when the route is registered.
+func RequireFeature(features config.FeaturesConfig, name string) gin.HandlerFunc {
+	if features.Enabled(name) {
+		return func(c *gin.Context) {
+			c.Next()
 		}
+	}
 
-		logger.WithFields(map[string]interface{}{
-			"user_id":   userID,
-			"user_type": userType,
-		}).Info("RefreshTokenMiddleware: token validated successfully")
-
-		c.Set("userID", userID)
-		c.Set("userType", userType)
-		c.Next()
+	return func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "not found"})
 	}
 }
 
 func RequireRole(requiredRole string, logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userType := c.GetString(ContextUserType)
+		userType := authctx.FromContext(c.Request.Context()).Type
 
 		if userType != requiredRole {
 			logger.WithFields(map[string]interface{}{
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/entity"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeAPITokens map[string]string
+
+func (f fakeAPITokens) Authenticate(_ context.Context, token string) (string, error) {
+	sellerID, ok := f[token]
+	if !ok {
+		return "", errors.New("unknown token")
+	}
+	return sellerID, nil
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestJWTManager(logger *logrus.Logger) jwt.JWTManager {
+	return jwt.NewJWTManager(nil, logger, config.Config{
+		JWT: config.JWTConfig{
+			SecretKey:        "test-secret",
+			ExpiresIn:        time.Minute,
+			RefreshExpiresIn: time.Hour,
+			RequireTokenType: true,
+		},
+	})
+}
+
+// newTestRouter serves GET /me behind the given middleware and answers with
+// the caller the handler finds in the request context.
+func newTestRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handlers = append(handlers, func(c *gin.Context) {
+		c.JSON(http.StatusOK, authctx.FromContext(c.Request.Context()))
+	})
+	r.GET("/me", handlers...)
+	return r
+}
+
+func serve(r *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAccessTokenMiddlewareSetsCaller(t *testing.T) {
+	logger := newTestLogger()
+	jwtManager := newTestJWTManager(logger)
+	r := newTestRouter(AccessTokenMiddleware(jwtManager, logger))
+
+	token, err := jwtManager.GenerateAccessToken(&entity.User{ID: "user-1", UserType: UserTypeCustomer})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	w := serve(r, "Authorization", "Bearer "+token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if want := `{"ID":"user-1","Type":"customer","ImpersonatorID":""}`; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body, want)
+	}
+}
+
+func TestAccessTokenMiddlewareSetsImpersonator(t *testing.T) {
+	logger := newTestLogger()
+	jwtManager := newTestJWTManager(logger)
+	r := newTestRouter(AccessTokenMiddleware(jwtManager, logger))
+
+	token, _, err := jwtManager.GenerateImpersonationToken(&entity.User{ID: "user-1", UserType: UserTypeSeller}, "admin-1", time.Minute)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	w := serve(r, "Authorization", "Bearer "+token)
+	if want := `{"ID":"user-1","Type":"seller","ImpersonatorID":"admin-1"}`; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body, want)
+	}
+}
+
+func TestAccessTokenMiddlewareRejectsMissingToken(t *testing.T) {
+	logger := newTestLogger()
+	r := newTestRouter(AccessTokenMiddleware(newTestJWTManager(logger), logger))
+
+	if w := serve(r, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAccessOrAPITokenMiddlewareSetsSeller(t *testing.T) {
+	logger := newTestLogger()
+	r := newTestRouter(
+		AccessOrAPITokenMiddleware(newTestJWTManager(logger), fakeAPITokens{"api-token": "seller-1"}, logger),
+		RequireRole(UserTypeSeller, logger),
+	)
+
+	w := serve(r, HeaderAPIToken, "api-token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if want := `{"ID":"seller-1","Type":"seller","ImpersonatorID":""}`; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body, want)
+	}
+
+	if w := serve(r, HeaderAPIToken, "unknown"); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireRoleReadsCaller(t *testing.T) {
+	logger := newTestLogger()
+	jwtManager := newTestJWTManager(logger)
+	r := newTestRouter(AccessTokenMiddleware(jwtManager, logger), RequireRole(UserTypeAdmin, logger))
+
+	for userType, want := range map[string]int{
+		UserTypeAdmin:    http.StatusOK,
+		UserTypeCustomer: http.StatusForbidden,
+	} {
+		token, err := jwtManager.GenerateAccessToken(&entity.User{ID: "user-1", UserType: userType})
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		if w := serve(r, "Authorization", "Bearer "+token); w.Code != want {
+			t.Errorf("%s: status = %d, want %d", userType, w.Code, want)
+		}
+	}
+}
+
+func TestTimeoutSkipsExemptRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Timeout(time.Minute, "/export"))
+	hasDeadline := func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, ok)
+	}
+	r.GET("/me", hasDeadline)
+	r.GET("/export", hasDeadline)
+
+	for path, want := range map[string]string{"/me": "true", "/export": "false"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: has deadline = %s, want %s", path, w.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutRetryAfter matches the Retry-After the responder sends with 503s.
+const timeoutRetryAfter = 5
+
+// Timeout puts a deadline of d on the request context. Queries still running
+// at the deadline fail with TIMEOUT, which the responder turns into a 503.
+// If the handler returns past the deadline without having written anything,
+// the 503 is written here.
+//
+// Routes listed in exempt, by their gin route path, run without the
+// deadline. They are meant for streaming endpoints whose duration grows with
+// the size of the body.
+func Timeout(d time.Duration, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if c.Writer.Written() || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(timeoutRetryAfter))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "request timed out",
+		})
+	}
+}
@@ -1,38 +1,59 @@
 package product
 
 import (
+	"marketplace/internal/entity"
+	"marketplace/internal/handler/middleware"
 	"marketplace/internal/handler/response"
+	imagesUsecase "marketplace/internal/usecase/images"
 	usecase "marketplace/internal/usecase/product"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
 	appError "marketplace/pkg/errors"
 	"marketplace/pkg/validator"
 	"net/http"
 	"strconv"
 
 	"marketplace/pkg/dto"
+	"marketplace/pkg/money"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+)
+
+const (
+	// productImagesLimit is how many images are embedded in a product payload.
+	productImagesLimit = 20
+
+	expandImages = "images"
 )
 
 type productHandler struct {
 	usecase   usecase.ProductUsecase
+	images    imagesUsecase.ImageUsecase
 	validate  validator.Validator
 	responder *response.Responder
+	binder    *response.Binder
+	cfg       config.ProductConfig
+	prices    money.Formatter
 }
 
-func NewProductHandler(usecase usecase.ProductUsecase, logger *logrus.Logger) *productHandler {
+func NewProductHandler(usecase usecase.ProductUsecase, images imagesUsecase.ImageUsecase, responder *response.Responder, binder *response.Binder, cfg config.ProductConfig, prices money.Formatter) *productHandler {
 	return &productHandler{
 		usecase:   usecase,
-		responder: response.New(logger),
+		images:    images,
+		responder: responder,
+		binder:    binder,
 		validate:  validator.NewValidator(),
+		cfg:       cfg,
+		prices:    prices,
 	}
 }
 
 func (h *productHandler) Create(c *gin.Context) {
 	var req dto.CreateProductRequest
 	categoryID := c.Param("categoryID")
+	sellerID := authctx.FromContext(c.Request.Context()).ID
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := h.binder.BindJSON(c, &req); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -42,7 +63,7 @@ func (h *productHandler) Create(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.usecase.Create(c, &req, categoryID)
+	resp, err := h.usecase.Create(c.Request.Context(), &req, categoryID, sellerID)
 	if err != nil {
 		h.responder.Error(c, err)
 		return
@@ -51,23 +72,60 @@ func (h *productHandler) Create(c *gin.Context) {
 	h.responder.Success(c, http.StatusCreated, resp)
 }
 
+func (h *productHandler) GetByID(c *gin.Context) {
+	product, err := h.usecase.GetByID(c.Request.Context(), c.Param("productID"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.detail(c, product)
+}
+
 func (h *productHandler) GetByTitle(c *gin.Context) {
 	title := c.Param("title")
 
-	product, err := h.usecase.GetByTitle(c, title)
+	product, err := h.usecase.GetByTitle(c.Request.Context(), title)
 	if err != nil {
 		h.responder.Error(c, err)
 		return
 	}
 
-	h.responder.Success(c, http.StatusOK, product)
+	h.detail(c, product)
+}
+
+// detail responds with the product and its images, the payload shared by
+// GetByID and GetByTitle.
+func (h *productHandler) detail(c *gin.Context, product *entity.Product) {
+	images, err := h.images.ListByProductID(c.Request.Context(), product.ID, productImagesLimit, 0)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, dto.ProductDetailResponse{
+		ID:           product.ID,
+		SellerID:     product.SellerID,
+		CategoryID:   product.CategoryID,
+		Title:        product.Title,
+		Description:  product.Description,
+		Price:        product.Price,
+		PriceDisplay: h.prices.Format(product.Price),
+		Stock:        product.Stock,
+		IsActive:     product.IsActive,
+		Version:      product.Version,
+		Featured:     product.Featured,
+		CreatedAt:    product.CreatedAt,
+		UpdatedAt:    product.UpdatedAt,
+		Images:       images,
+	})
 }
 
 func (h *productHandler) Update(c *gin.Context) {
 	var req dto.UpdateProductRequest
 	productId := c.Param("productID")
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := h.binder.BindJSON(c, &req); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -77,7 +135,7 @@ func (h *productHandler) Update(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.usecase.Update(c, &req, productId)
+	resp, err := h.usecase.Update(c.Request.Context(), &req, productId, authctx.FromContext(c.Request.Context()).ID)
 	if err != nil {
 		h.responder.Error(c, err)
 		return
@@ -89,7 +147,7 @@ func (h *productHandler) Update(c *gin.Context) {
 func (h *productHandler) Delete(c *gin.Context) {
 	productID := c.Param("productID")
 
-	if err := h.usecase.Delete(c, productID); err != nil {
+	if err := h.usecase.Delete(c.Request.Context(), productID, authctx.FromContext(c.Request.Context()).ID); err != nil {
 		h.responder.Error(c, err)
 		return
 	}
@@ -97,29 +155,285 @@ func (h *productHandler) Delete(c *gin.Context) {
 	h.responder.NoContent(c)
 }
 
+func (h *productHandler) SetActiveByCategory(c *gin.Context) {
+	var req dto.SetActiveRequest
+	categoryID := c.Param("categoryID")
+
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appError.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	updated, err := h.usecase.SetActiveByCategory(c.Request.Context(), categoryID, *req.IsActive)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, dto.SetActiveResponse{Updated: updated})
+}
+
+func (h *productHandler) SetFeatured(c *gin.Context) {
+	var req dto.SetFeaturedRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appError.NewAppError("VALIDATION", "invalid input", err))
+		return
+	}
+
+	if err := h.usecase.SetFeatured(c.Request.Context(), c.Param("productID"), *req.Featured); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+// ListFeatured returns featured products for promotion slots.
+func (h *productHandler) ListFeatured(c *gin.Context) {
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	page, err := h.usecase.ListFeatured(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, page.Items, page.Meta())
+}
+
+// ListPopular returns the most viewed products.
+func (h *productHandler) ListPopular(c *gin.Context) {
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	products, err := h.usecase.ListPopular(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, products, dto.NewPageMeta(limit, offset))
+}
+
+// Search matches the q parameter against product titles and descriptions.
+func (h *productHandler) Search(c *gin.Context) {
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	filter := dto.ProductListFilter{InStockOnly: h.inStockOnly(c)}
+	products, err := h.usecase.Search(c.Request.Context(), c.Query("q"), filter.InStockOnly, limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, products, dto.ProductListMeta{
+		PageMeta: dto.NewPageMeta(limit, offset),
+		Filter:   filter,
+		Sort:     dto.ProductSortNewest,
+	})
+}
+
+// GetCard returns the product with its category and seller display names.
+func (h *productHandler) GetCard(c *gin.Context) {
+	card, err := h.usecase.GetCard(c.Request.Context(), c.Param("productID"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, card)
+}
+
 func (h *productHandler) List(c *gin.Context) {
 	categoryID := c.Param("categoryID")
-	limitStr := c.Query("limit")
-	limit := 10
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
-			limit = parsedLimit
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	filter := dto.ProductListFilter{InStockOnly: h.inStockOnly(c)}
+	if err := parseListFilter(c, &filter); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	sort, ok := dto.ParseProductSort(c.Query("sort"))
+	if !ok {
+		h.responder.Error(c, appError.NewAppError("VALIDATION", "sort must be one of newest, price_asc, price_desc, title", nil))
+		return
+	}
+
+	page, err := h.usecase.List(c.Request.Context(), categoryID, filter, sort, limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	products := page.Items
+	if c.Query("expand") == expandImages {
+		ids := make([]string, len(products))
+		for i := range products {
+			ids[i] = products[i].ID
 		}
+		images, err := h.images.ListByProductIDs(c.Request.Context(), ids, productImagesLimit)
+		if err != nil {
+			h.responder.Error(c, err)
+			return
+		}
+		for i := range products {
+			products[i].Images = images[products[i].ID]
+		}
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, products, dto.ProductListMeta{
+		PageMeta: page.Meta(),
+		Filter:   filter,
+		Sort:     sort,
+	})
+}
+
+// inStockOnly reads the inStockOnly query parameter. Without it, the
+// configured default applies to everyone but sellers.
+func (h *productHandler) inStockOnly(c *gin.Context) bool {
+	if inStockOnly, err := strconv.ParseBool(c.Query("inStockOnly")); err == nil {
+		return inStockOnly
+	}
+	return h.cfg.InStockOnly && authctx.FromContext(c.Request.Context()).Type != middleware.UserTypeSeller
+}
+
+// parseListFilter reads the optional min_price, max_price and is_active query
+// parameters. A parameter that is absent leaves its filter unset.
+func parseListFilter(c *gin.Context, filter *dto.ProductListFilter) error {
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+	} {
+		raw, ok := c.GetQuery(p.name)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return appError.NewAppError(response.CodeBadRequest, p.name+" must be a number", err)
+		}
+		*p.dst = &v
 	}
 
-	offsetStr := c.Query("offset")
-	offset := 0
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
-			offset = parsedOffset
+	if raw, ok := c.GetQuery("is_active"); ok {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return appError.NewAppError(response.CodeBadRequest, "is_active must be a boolean", err)
 		}
+		filter.IsActive = &v
+	}
+
+	return nil
+}
+
+func (h *productHandler) CheckAvailability(c *gin.Context) {
+	var req dto.AvailabilityRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	if err := h.validate.Validate(req); err != nil {
+		h.responder.Error(c, appError.NewAppError("VALIDATION", "invalid input", err))
+		return
 	}
 
-	products, err := h.usecase.List(c, categoryID, limit, offset)
+	availability, err := h.usecase.CheckAvailability(c.Request.Context(), req.ProductIDs)
 	if err != nil {
 		h.responder.Error(c, err)
 		return
 	}
 
-	h.responder.Success(c, http.StatusOK, products)
+	h.responder.Success(c, http.StatusOK, availability)
+}
+
+// CategoryStats returns aggregate product figures for one category.
+func (h *productHandler) CategoryStats(c *gin.Context) {
+	stats, err := h.usecase.CategoryStats(c.Request.Context(), c.Param("categoryID"))
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, stats)
+}
+
+// HardDelete permanently removes a product.
+func (h *productHandler) HardDelete(c *gin.Context) {
+	if err := h.usecase.HardDelete(c.Request.Context(), c.Param("productID")); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+// ListPending returns products awaiting moderation, oldest first.
+func (h *productHandler) ListPending(c *gin.Context) {
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	products, err := h.usecase.ListPendingModeration(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, products, dto.NewPageMeta(limit, offset))
+}
+
+func (h *productHandler) Approve(c *gin.Context) {
+	if err := h.usecase.Approve(c.Request.Context(), c.Param("productID")); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+func (h *productHandler) Reject(c *gin.Context) {
+	var req dto.RejectProductRequest
+	if err := h.binder.BindJSON(c, &req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	if err := h.usecase.Reject(c.Request.Context(), c.Param("productID"), req); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
 }
@@ -0,0 +1,115 @@
+package product
+
+import (
+	"context"
+	"io"
+	"marketplace/internal/entity"
+	"marketplace/internal/handler/response"
+	imagesUsecase "marketplace/internal/usecase/images"
+	usecase "marketplace/internal/usecase/product"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	appError "marketplace/pkg/errors"
+	"marketplace/pkg/money"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeUsecase serves products from a map. Methods the tests do not reach
+// panic through the nil embedded interface.
+type fakeUsecase struct {
+	usecase.ProductUsecase
+	products map[string]*entity.Product
+	// createdBy records the seller id each Create call received.
+	createdBy []string
+}
+
+func (f *fakeUsecase) Create(_ context.Context, _ *dto.CreateProductRequest, categoryID, sellerID string) (*dto.ProductResponse, error) {
+	f.createdBy = append(f.createdBy, sellerID)
+	return &dto.ProductResponse{ID: "p1", SellerID: sellerID, CategoryID: categoryID}, nil
+}
+
+func (f *fakeUsecase) GetByTitle(_ context.Context, title string) (*entity.Product, error) {
+	for _, p := range f.products {
+		if p.Title == title {
+			return p, nil
+		}
+	}
+	return nil, appError.NewAppError("NOT_FOUND", "product not found", appError.ErrNotFound)
+}
+
+type fakeImages struct {
+	imagesUsecase.ImageUsecase
+}
+
+func (fakeImages) ListByProductID(context.Context, string, int, int) ([]dto.ImageDTO, error) {
+	return []dto.ImageDTO{}, nil
+}
+
+func newTestRouter(uc usecase.ProductUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	h := NewProductHandler(uc, fakeImages{}, response.New(logger, response.Options{}), response.NewBinder(false, 0), config.ProductConfig{}, money.NewFormatter(config.MoneyConfig{}))
+	r := gin.New()
+	r.GET("/products/title/:title", h.GetByTitle)
+	r.POST("/categories/:categoryID/products", asCaller(authctx.User{ID: "seller-1", Type: "seller"}), h.Create)
+	return r
+}
+
+// asCaller stands in for the auth middleware.
+func asCaller(user authctx.User) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(authctx.WithUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+func get(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestGetByTitleUnknownIs404(t *testing.T) {
+	r := newTestRouter(&fakeUsecase{products: map[string]*entity.Product{}})
+
+	if w := get(r, "/products/title/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusNotFound, w.Body)
+	}
+}
+
+func TestGetByTitleFound(t *testing.T) {
+	r := newTestRouter(&fakeUsecase{products: map[string]*entity.Product{
+		"p1": {ID: "p1", Title: "Widget", IsActive: true},
+	}})
+
+	if w := get(r, "/products/title/Widget"); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d; body %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestCreateTakesSellerFromCaller(t *testing.T) {
+	uc := &fakeUsecase{products: map[string]*entity.Product{}}
+	r := newTestRouter(uc)
+
+	body := `{"seller_id": "forged", "title": "Widget", "price": 10}`
+	req := httptest.NewRequest(http.MethodPost, "/categories/c1/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusCreated, w.Body)
+	}
+	if len(uc.createdBy) != 1 || uc.createdBy[0] != "seller-1" {
+		t.Errorf("usecase got sellers %v, want [seller-1]", uc.createdBy)
+	}
+}
@@ -3,17 +3,33 @@ package product
 import (
 	"marketplace/internal/adapter/jwt"
 	"marketplace/internal/handler/middleware"
+	"marketplace/pkg/config"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func RegisterProductRoutes(rg *gin.RouterGroup, h *productHandler, jwtManager jwt.JWTManager, log *logrus.Logger) {
+func RegisterProductRoutes(rg *gin.RouterGroup, h *productHandler, jwtManager jwt.JWTManager, apiTokens middleware.APITokenAuthenticator, features config.FeaturesConfig, log *logrus.Logger) {
+	rg.GET("/products/featured", h.ListFeatured)
+	rg.GET("/products/popular", h.ListPopular)
+
+	readGroup := rg.Group("/")
+	readGroup.Use(middleware.AccessOrAPITokenMiddleware(jwtManager, apiTokens, log))
+	{
+		readGroup.GET("/products/title/:title", h.GetByTitle)
+		readGroup.GET("/products/search", middleware.RequireFeature(features, config.FeatureSearch), h.Search)
+		readGroup.GET("/products/:productID", h.GetByID)
+		readGroup.GET("/products/:productID/card", h.GetCard)
+		readGroup.GET("/categories/:categoryID/products", h.List)
+		readGroup.GET("/categories/:categoryID/stats", h.CategoryStats)
+	}
+
 	publicGroup := rg.Group("/")
 	publicGroup.Use(middleware.AccessTokenMiddleware(jwtManager, log))
 	{
-		publicGroup.GET("/products/title/:title", h.GetByTitle)
-		publicGroup.GET("/categories/:categoryID/products", h.List)
+		publicGroup.POST("/products/availability", h.CheckAvailability)
+		// Who may feature which product is decided by the usecase policy.
+		publicGroup.PATCH("/products/:productID/featured", h.SetFeatured)
 	}
 
 	sellerGroup := rg.Group("/")
@@ -24,4 +40,15 @@ func RegisterProductRoutes(rg *gin.RouterGroup, h *productHandler, jwtManager jw
 		sellerGroup.PUT("/products/:productID", h.Update)
 		sellerGroup.DELETE("/products/:productID", h.Delete)
 	}
+
+	adminGroup := rg.Group("/")
+	adminGroup.Use(middleware.AccessTokenMiddleware(jwtManager, log))
+	adminGroup.Use(middleware.RequireRole(middleware.UserTypeAdmin, log))
+	{
+		adminGroup.PATCH("/categories/:categoryID/products/active", h.SetActiveByCategory)
+		adminGroup.GET("/admin/products/pending", h.ListPending)
+		adminGroup.DELETE("/admin/products/:productID", h.HardDelete)
+		adminGroup.POST("/admin/products/:productID/approve", h.Approve)
+		adminGroup.POST("/admin/products/:productID/reject", h.Reject)
+	}
 }
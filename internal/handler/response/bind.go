@@ -0,0 +1,148 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	apperrors "marketplace/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// CodeBadRequest marks request bodies or query strings that could not be
+// decoded at all, as opposed to decoded input failing validation.
+const CodeBadRequest = "BAD_REQUEST"
+
+// Binder decodes request bodies and query parameters.
+type Binder struct {
+	// strictJSON makes BindJSON reject fields the target struct does not
+	// have.
+	strictJSON bool
+	// maxOffset caps the offset query parameter of list endpoints. Zero
+	// disables the cap.
+	maxOffset int
+}
+
+func NewBinder(strictJSON bool, maxOffset int) *Binder {
+	return &Binder{strictJSON: strictJSON, maxOffset: maxOffset}
+}
+
+// BindJSON decodes the request body into obj. Decoding failures come back
+// as a BAD_REQUEST AppError that says what was wrong with the body.
+func (b *Binder) BindJSON(c *gin.Context, obj interface{}) error {
+	if b.strictJSON {
+		return bindStrictJSON(c, obj)
+	}
+	if err := c.ShouldBindJSON(obj); err != nil {
+		return apperrors.NewAppError(CodeBadRequest, bindMessage(err), err)
+	}
+	return nil
+}
+
+// bindStrictJSON is ShouldBindJSON with unknown fields disallowed. The error
+// for unknown fields lists every unknown top-level key, not just the first.
+func bindStrictJSON(c *gin.Context, obj interface{}) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return apperrors.NewAppError(CodeBadRequest, bindMessage(io.EOF), io.EOF)
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return apperrors.NewAppError(CodeBadRequest, "failed to read request body", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			fields := unknownFields(body, obj)
+			if len(fields) == 0 {
+				fields = []string{strings.Trim(field, `"`)}
+			}
+			return apperrors.NewAppError(CodeBadRequest, "unknown fields: "+strings.Join(fields, ", "), err)
+		}
+		return apperrors.NewAppError(CodeBadRequest, bindMessage(err), err)
+	}
+
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		return apperrors.NewAppError(CodeBadRequest, bindMessage(err), err)
+	}
+	return nil
+}
+
+// unknownFields returns the sorted top-level keys of body that obj, a
+// pointer to a struct, has no JSON field for.
+func unknownFields(body []byte, obj interface{}) []string {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			// Promoted fields are not worth resolving here; the caller
+			// falls back to the decoder's first unknown field.
+			return nil
+		}
+		if name == "" {
+			name = f.Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	var unknown []string
+	for key := range raw {
+		// encoding/json matches field names case-insensitively.
+		if !known[strings.ToLower(key)] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// BindQuery is BindJSON for query parameters.
+func (b *Binder) BindQuery(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		return apperrors.NewAppError(CodeBadRequest, "invalid query parameters", err)
+	}
+	return nil
+}
+
+func bindMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is truncated JSON"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("field %q must be %s", typeErr.Field, typeErr.Type)
+		}
+		return fmt.Sprintf("request body must be %s", typeErr.Type)
+	default:
+		return "invalid request body"
+	}
+}
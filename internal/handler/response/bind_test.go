@@ -0,0 +1,100 @@
+package response
+
+import (
+	"errors"
+	apperrors "marketplace/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindTarget struct {
+	Name  string  `json:"name" binding:"required"`
+	Price float64 `json:"price"`
+	Meta  struct {
+		Color string `json:"color"`
+	} `json:"meta"`
+}
+
+type bindBase struct {
+	ID string `json:"id"`
+}
+
+type bindEmbedded struct {
+	bindBase
+	Extra string `json:"extra"`
+}
+
+// bind runs BindJSON over body and returns the AppError message, or "" when
+// binding succeeded.
+func bind(t *testing.T, strict bool, body string, obj interface{}) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	err := NewBinder(strict, 0).BindJSON(c, obj)
+	if err == nil {
+		return ""
+	}
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code() != CodeBadRequest {
+		t.Fatalf("error = %v, want a %s AppError", err, CodeBadRequest)
+	}
+	return appErr.Message()
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"valid", `{"name":"lamp","price":10}`, ""},
+		{"empty", ``, "request body is empty"},
+		{"truncated", `{"name":`, "request body is truncated JSON"},
+		{"malformed", `{"name" "lamp"}`, "malformed JSON at offset 9"},
+		{"wrong type", `{"name":"lamp","price":"ten"}`, `field "price" must be float64`},
+		{"wrong nested type", `{"name":"lamp","meta":{"color":1}}`, `field "meta.color" must be string`},
+		{"fails validation", `{"price":10}`, "invalid request body"},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			if got := bind(t, strict, tt.body, &bindTarget{}); got != tt.want {
+				t.Errorf("strict %v, %s: message = %q, want %q", strict, tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestBindJSONUnknownFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		obj    interface{}
+		strict string
+	}{
+		{"top level", `{"name":"lamp","zeta":1,"alpha":2}`, &bindTarget{}, "unknown fields: alpha, zeta"},
+		// Names match case-insensitively, as encoding/json matches them.
+		{"other case", `{"NAME":"lamp"}`, &bindTarget{}, ""},
+		// Only top-level keys are resolved; nested ones fall back to the
+		// decoder's first unknown field.
+		{"nested", `{"name":"lamp","meta":{"size":1}}`, &bindTarget{}, "unknown fields: size"},
+		{"embedded known", `{"id":"1","extra":"x"}`, &bindEmbedded{}, ""},
+		{"embedded unknown", `{"id":"1","zeta":1}`, &bindEmbedded{}, "unknown fields: zeta"},
+	}
+
+	for _, tt := range tests {
+		if got := bind(t, true, tt.body, tt.obj); got != tt.strict {
+			t.Errorf("%s: strict message = %q, want %q", tt.name, got, tt.strict)
+		}
+		if got := bind(t, false, tt.body, tt.obj); got != "" {
+			t.Errorf("%s: lenient message = %q, want success", tt.name, got)
+		}
+	}
+}
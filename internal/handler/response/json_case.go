@@ -0,0 +1,81 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	JSONCaseSnake = "snake"
+	JSONCaseCamel = "camel"
+)
+
+// wantsCamelCase reports whether the response keys should be camelCase. A
+// "case" parameter in the Accept header, e.g.
+// "application/json; case=camel", overrides the configured default.
+func (r *Responder) wantsCamelCase(c *gin.Context) bool {
+	for _, part := range strings.Split(c.GetHeader("Accept"), ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && strings.EqualFold(key, "case") {
+			return strings.EqualFold(value, JSONCaseCamel)
+		}
+	}
+	return r.jsonCase == JSONCaseCamel
+}
+
+// camelizeKeys re-encodes v with every object key converted from snake_case
+// to camelCase.
+func camelizeKeys(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return camelizeValue(generic), nil
+}
+
+func camelizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[snakeToCamel(k)] = camelizeValue(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range val {
+			val[i] = camelizeValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func snakeToCamel(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+
+	parts := strings.Split(s, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, p := range parts[1:] {
+		if p == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package response
+
+import (
+	"fmt"
+	apperrors "marketplace/pkg/errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page reads the limit and offset query parameters. A missing or malformed
+// value falls back to defaultLimit and 0; range checks are left to the
+// usecases. Offsets beyond the configured maximum are rejected, since
+// Postgres has to scan and discard every skipped row.
+func (b *Binder) Page(c *gin.Context, defaultLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		limit = parsedLimit
+	}
+	if parsedOffset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		offset = parsedOffset
+	}
+
+	if b.maxOffset > 0 && offset > b.maxOffset {
+		return 0, 0, apperrors.NewAppError("INVALID_INPUT", fmt.Sprintf("offset must be at most %d; narrow the query or use cursor pagination", b.maxOffset), nil)
+	}
+	return limit, offset, nil
+}
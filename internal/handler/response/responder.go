@@ -1,25 +1,80 @@
 package response
 
 import (
+	"marketplace/pkg/authctx"
 	apperrors "marketplace/pkg/errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// retryAfterSeconds is sent with 503 responses so clients back off.
+	retryAfterSeconds = 5
+
+	// statusClientClosedRequest is the non-standard nginx status for a
+	// client that went away before the response was ready.
+	statusClientClosedRequest = 499
+)
+
+// Options configures a Responder.
+type Options struct {
+	// JSONCase is the key style of success payloads when the client does
+	// not ask for one. Unknown values keep snake_case.
+	JSONCase string
+	// ErrorDetails adds the full error text to error responses.
+	ErrorDetails bool
+}
+
 type Responder struct {
-	log *logrus.Logger
+	log          *logrus.Logger
+	jsonCase     string
+	errorDetails bool
 }
 
-func New(log *logrus.Logger) *Responder {
-	return &Responder{log: log}
+func New(log *logrus.Logger, opts Options) *Responder {
+	jsonCase := JSONCaseSnake
+	if opts.JSONCase == JSONCaseCamel {
+		jsonCase = JSONCaseCamel
+	}
+	return &Responder{log: log, jsonCase: jsonCase, errorDetails: opts.ErrorDetails}
 }
 
 func (r *Responder) Success(c *gin.Context, status int, data interface{}) {
+	if r.wantsCamelCase(c) {
+		camel, err := camelizeKeys(data)
+		if err != nil {
+			r.log.WithError(err).Warn("Responder: failed to convert keys to camelCase")
+		} else {
+			data = camel
+		}
+	}
+
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// SuccessWithMeta is Success with an extra "meta" object next to the data.
+// List endpoints pass a dto.PageMeta, or a struct embedding one.
+func (r *Responder) SuccessWithMeta(c *gin.Context, status int, data interface{}, meta interface{}) {
+	if r.wantsCamelCase(c) {
+		camelData, dataErr := camelizeKeys(data)
+		camelMeta, metaErr := camelizeKeys(meta)
+		if dataErr != nil || metaErr != nil {
+			r.log.Warn("Responder: failed to convert keys to camelCase")
+		} else {
+			data, meta = camelData, camelMeta
+		}
+	}
+
 	c.JSON(status, gin.H{
 		"success": true,
 		"data":    data,
+		"meta":    meta,
 	})
 }
 
@@ -28,37 +83,88 @@ func (r *Responder) NoContent(c *gin.Context) {
 }
 
 func (r *Responder) Error(c *gin.Context, err error) {
+	if unavailable := apperrors.UnavailableError(err); unavailable != nil {
+		r.log.WithFields(map[string]interface{}{
+			"code":  unavailable.Code(),
+			"error": err.Error(),
+		}).Warn("Responder: service unavailable")
+		r.write(c, unavailable)
+		return
+	}
+
+	if ctxErr := apperrors.ContextError(err); ctxErr != nil {
+		// Client disconnects and deadlines are expected; keep them out of
+		// the error log.
+		r.log.WithFields(map[string]interface{}{
+			"code":  ctxErr.Code(),
+			"error": err.Error(),
+		}).Info("Responder: request aborted")
+		r.write(c, ctxErr)
+		return
+	}
+
 	appErr, ok := err.(*apperrors.AppError)
 	if !ok {
 		r.log.Error("Responder: untyped error: ", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
+		body := gin.H{
 			"success": false,
 			"error":   "internal server error",
-		})
+		}
+		if r.errorDetails {
+			body["debug"] = err.Error()
+		}
+		c.JSON(http.StatusInternalServerError, body)
 		return
 	}
 
-	r.log.WithFields(map[string]interface{}{
+	fields := map[string]interface{}{
 		"code":    appErr.Code(),
 		"message": appErr.Message(),
 		"error":   appErr.Error(),
-	}).Error("Responder: application error")
+	}
+	if impersonatorID := authctx.FromContext(c.Request.Context()).ImpersonatorID; impersonatorID != "" {
+		fields["impersonator_id"] = impersonatorID
+	}
+	r.log.WithFields(fields).Error("Responder: application error")
 
+	r.write(c, appErr)
+}
+
+func (r *Responder) write(c *gin.Context, appErr *apperrors.AppError) {
 	status := mapErrorCodeToStatus(appErr.Code())
-	c.JSON(status, gin.H{
+	if status == http.StatusServiceUnavailable {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds))
+	}
+	body := gin.H{
 		"success": false,
 		"error":   appErr.Message(),
-	})
+	}
+	if r.errorDetails {
+		body["debug"] = appErr.Error()
+	}
+	c.JSON(status, body)
 }
 
 func mapErrorCodeToStatus(code string) int {
 	switch code {
 	case "NOT_FOUND":
 		return http.StatusNotFound
-	case "VALIDATION", "INVALID_TYPE", "INVALID_PAYLOAD":
+	case CodeBadRequest, "VALIDATION", "INVALID_TYPE", "INVALID_PAYLOAD", "INVALID_INPUT":
 		return http.StatusBadRequest
 	case "INVALID_CREDENTIALS", "INVALID_TOKEN":
 		return http.StatusUnauthorized
+	case "FORBIDDEN":
+		return http.StatusForbidden
+	case "DUPLICATE", "CONFLICT":
+		return http.StatusConflict
+	case "BUSINESS_ERR":
+		return http.StatusUnprocessableEntity
+	case "RATE_LIMITED":
+		return http.StatusTooManyRequests
+	case apperrors.CodeUnavailable, apperrors.CodeTimeout:
+		return http.StatusServiceUnavailable
+	case apperrors.CodeRequestCanceled:
+		return statusClientClosedRequest
 	case "UPDATE_FAIL", "DELETE_FAIL", "USER_CREATE_FAIL":
 		return http.StatusInternalServerError
 	default:
@@ -0,0 +1,37 @@
+package response
+
+import (
+	"io"
+	"marketplace/pkg/authctx"
+	apperrors "marketplace/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestErrorLogsImpersonatorFromCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	hook := test.NewLocal(logger)
+	responder := New(logger, Options{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request = req.WithContext(authctx.WithUser(req.Context(), authctx.User{ID: "seller-1", Type: "seller", ImpersonatorID: "admin-1"}))
+
+	responder.Error(c, apperrors.NewAppError("NOT_FOUND", "product not found", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	entry := hook.LastEntry()
+	if entry == nil || entry.Data["impersonator_id"] != "admin-1" {
+		t.Errorf("log entry %+v, want impersonator_id admin-1", entry)
+	}
+}
@@ -0,0 +1,27 @@
+package seller
+
+import (
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/handler/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// StreamingRoutes are the routes that stream a whole catalog. They are
+// exempt from server.handler_timeout.
+var StreamingRoutes = []string{"/seller/products/export", "/seller/products/import"}
+
+func RegisterSellerRoutes(rg *gin.RouterGroup, h *SellerHandler, jwtManager jwt.JWTManager, log *logrus.Logger) {
+	seller := rg.Group("/seller")
+	seller.Use(middleware.AccessTokenMiddleware(jwtManager, log))
+	seller.Use(middleware.RequireRole(middleware.UserTypeSeller, log))
+	{
+		seller.POST("/api-token", h.GenerateAPIToken)
+		seller.DELETE("/api-token", h.RevokeAPIToken)
+		seller.GET("/stats", h.Stats)
+		seller.GET("/products", h.Products)
+		seller.GET("/products/export", h.Export)
+		seller.POST("/products/import", h.Import)
+	}
+}
@@ -0,0 +1,416 @@
+package seller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"marketplace/internal/handler/response"
+	"marketplace/internal/usecase/apitoken"
+	imagesUsecase "marketplace/internal/usecase/images"
+	productUsecase "marketplace/internal/usecase/product"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	appErrors "marketplace/pkg/errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+)
+
+type SellerHandler struct {
+	apiTokenUsecase apitoken.APITokenUsecase
+	productUsecase  productUsecase.ProductUsecase
+	imageUsecase    imagesUsecase.ImageUsecase
+	responder       *response.Responder
+	binder          *response.Binder
+	logger          *logrus.Logger
+	cfg             config.ProductConfig
+}
+
+func NewSellerHandler(apiTokenUsecase apitoken.APITokenUsecase, products productUsecase.ProductUsecase, images imagesUsecase.ImageUsecase, responder *response.Responder, binder *response.Binder, logger *logrus.Logger, cfg config.ProductConfig) *SellerHandler {
+	return &SellerHandler{
+		apiTokenUsecase: apiTokenUsecase,
+		productUsecase:  products,
+		imageUsecase:    images,
+		responder:       responder,
+		binder:          binder,
+		logger:          logger,
+		cfg:             cfg,
+	}
+}
+
+// GenerateAPIToken issues a new API token, replacing any previous one. The
+// plaintext token is only returned here and cannot be retrieved later.
+func (h *SellerHandler) GenerateAPIToken(c *gin.Context) {
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	resp, err := h.apiTokenUsecase.Generate(c.Request.Context(), sellerID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusCreated, resp)
+}
+
+func (h *SellerHandler) RevokeAPIToken(c *gin.Context) {
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	if err := h.apiTokenUsecase.Revoke(c.Request.Context(), sellerID); err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.NoContent(c)
+}
+
+// Stats returns the seller's product counts for the dashboard.
+func (h *SellerHandler) Stats(c *gin.Context) {
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	stats, err := h.productUsecase.SellerStats(c.Request.Context(), sellerID)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.Success(c, http.StatusOK, stats)
+}
+
+// Products lists the seller's own products, optionally narrowed to one
+// moderation status by the status query parameter.
+func (h *SellerHandler) Products(c *gin.Context) {
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	limit, offset, err := h.binder.Page(c, 10)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	products, err := h.productUsecase.ListSellerProducts(c.Request.Context(), sellerID, c.Query("status"), limit, offset)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	h.responder.SuccessWithMeta(c, http.StatusOK, products, dto.NewPageMeta(limit, offset))
+}
+
+// Export streams the seller's whole catalog as CSV or JSON (the default).
+// With images=true each product carries its image URLs. Rows are written as
+// they are read, so an error after the first page can only cut the body
+// short; it is logged.
+func (h *SellerHandler) Export(c *gin.Context) {
+	liftDeadlines(c)
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	format := c.DefaultQuery("format", exportFormatJSON)
+	if format != exportFormatCSV && format != exportFormatJSON {
+		h.responder.Error(c, appErrors.NewAppError("INVALID_INPUT", "format must be csv or json", nil))
+		return
+	}
+	withImages, _ := strconv.ParseBool(c.Query("images"))
+
+	var (
+		started bool
+		csvOut  *csv.Writer
+		first   = true
+	)
+	begin := func() error {
+		started = true
+		c.Header("Content-Disposition", `attachment; filename="products.`+format+`"`)
+		if format == exportFormatCSV {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Status(http.StatusOK)
+			csvOut = csv.NewWriter(c.Writer)
+			header := []string{"id", "category_id", "title", "description", "price", "stock", "is_active", "moderation_status", "created_at", "updated_at"}
+			if withImages {
+				header = append(header, "image_urls")
+			}
+			return csvOut.Write(header)
+		}
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		_, err := c.Writer.WriteString("[")
+		return err
+	}
+
+	err := h.productUsecase.ExportSellerProducts(c.Request.Context(), sellerID, func(rows []dto.ProductExportRow) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		var images map[string][]dto.ImageDTO
+		if withImages {
+			ids := make([]string, len(rows))
+			for i, row := range rows {
+				ids[i] = row.ID
+			}
+			// An export is a backup, so every image is included.
+			var err error
+			if images, err = h.imageUsecase.ListByProductIDs(c.Request.Context(), ids, 0); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			for _, image := range images[row.ID] {
+				row.ImageURLs = append(row.ImageURLs, image.URL)
+			}
+			if err := h.writeExportRow(c, csvOut, row, withImages, first); err != nil {
+				return err
+			}
+			first = false
+		}
+		if csvOut != nil {
+			csvOut.Flush()
+			if err := csvOut.Error(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			h.responder.Error(c, err)
+			return
+		}
+		h.logger.WithFields(logrus.Fields{
+			"operation": "export",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Error("Export aborted mid-stream")
+		c.Abort()
+		return
+	}
+
+	if !started {
+		if err := begin(); err != nil {
+			return
+		}
+	}
+	if csvOut != nil {
+		csvOut.Flush()
+		return
+	}
+	_, _ = c.Writer.WriteString("]")
+}
+
+func (h *SellerHandler) writeExportRow(c *gin.Context, csvOut *csv.Writer, row dto.ProductExportRow, withImages, first bool) error {
+	if csvOut != nil {
+		record := []string{
+			row.ID,
+			row.CategoryID,
+			row.Title,
+			row.Description,
+			strconv.FormatFloat(row.Price, 'f', 2, 64),
+			strconv.Itoa(row.Stock),
+			strconv.FormatBool(row.IsActive),
+			row.ModerationStatus,
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			row.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if withImages {
+			// URLs cannot contain spaces, so they are space separated.
+			record = append(record, strings.Join(row.ImageURLs, " "))
+		}
+		return csvOut.Write(record)
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if !first {
+		if _, err := c.Writer.WriteString(","); err != nil {
+			return err
+		}
+	}
+	_, err = c.Writer.Write(data)
+	return err
+}
+
+// errTooManyRows stops parsing an import once it exceeds MaxImportRows.
+var errTooManyRows = errors.New("too many rows")
+
+// liftDeadlines clears the server read and write deadlines for the streaming
+// routes, whose duration grows with the catalog. Writers that cannot set
+// deadlines, such as test recorders, are left alone.
+func liftDeadlines(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
+
+// Import creates products from an uploaded CSV or JSON catalog, in the
+// layout Export writes. The format comes from the format query parameter or
+// else the Content-Type. Rows that cannot be parsed are reported together
+// with the rows the usecase rejects.
+func (h *SellerHandler) Import(c *gin.Context) {
+	liftDeadlines(c)
+	sellerID := authctx.FromContext(c.Request.Context()).ID
+
+	format := c.Query("format")
+	if format == "" {
+		format = exportFormatJSON
+		if strings.HasPrefix(c.ContentType(), "text/csv") {
+			format = exportFormatCSV
+		}
+	}
+
+	var (
+		rows      []dto.ImportProductRow
+		parseErrs []dto.ImportRowError
+		err       error
+	)
+	switch format {
+	case exportFormatCSV:
+		rows, parseErrs, err = h.parseImportCSV(c.Request.Body)
+	case exportFormatJSON:
+		rows, parseErrs, err = h.parseImportJSON(c.Request.Body)
+	default:
+		err = appErrors.NewAppError("INVALID_INPUT", "format must be csv or json", nil)
+	}
+	if errors.Is(err, errTooManyRows) {
+		err = appErrors.NewAppError("INVALID_INPUT", fmt.Sprintf("import is limited to %d rows", h.cfg.MaxImportRows), err)
+	}
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+
+	result, err := h.productUsecase.ImportSellerProducts(c.Request.Context(), sellerID, rows)
+	if err != nil {
+		h.responder.Error(c, err)
+		return
+	}
+	result.Errors = append(parseErrs, result.Errors...)
+	sort.Slice(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+	result.Failed = len(result.Errors)
+
+	h.responder.Success(c, http.StatusOK, result)
+}
+
+// checkRowCount fails once n parsed rows exceed the import cap.
+func (h *SellerHandler) checkRowCount(n int) error {
+	if h.cfg.MaxImportRows > 0 && n > h.cfg.MaxImportRows {
+		return errTooManyRows
+	}
+	return nil
+}
+
+// parseImportCSV reads the body record by record. The header row names the
+// columns; category_id, title, description, price and stock are read and
+// any other column is ignored.
+func (h *SellerHandler) parseImportCSV(body io.Reader) ([]dto.ImportProductRow, []dto.ImportRowError, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, appErrors.NewAppError(response.CodeBadRequest, "csv header row is missing", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, appErrors.NewAppError(response.CodeBadRequest, "csv header has no title column", nil)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var (
+		rows      []dto.ImportProductRow
+		parseErrs []dto.ImportRowError
+	)
+	for n := 1; ; n++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err := h.checkRowCount(n); err != nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, appErrors.NewAppError(response.CodeBadRequest, "failed to read csv", err)
+			}
+			parseErrs = append(parseErrs, dto.ImportRowError{Row: n, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		req := dto.CreateProductRequest{
+			CategoryID:  field(record, "category_id"),
+			Title:       field(record, "title"),
+			Description: field(record, "description"),
+		}
+		if req.Price, err = strconv.ParseFloat(field(record, "price"), 64); err != nil {
+			parseErrs = append(parseErrs, dto.ImportRowError{Row: n, Error: "price must be a number"})
+			continue
+		}
+		if stock := field(record, "stock"); stock != "" {
+			if req.Stock, err = strconv.Atoi(stock); err != nil {
+				parseErrs = append(parseErrs, dto.ImportRowError{Row: n, Error: "stock must be an integer"})
+				continue
+			}
+		}
+		rows = append(rows, dto.ImportProductRow{Row: n, Product: req})
+	}
+
+	return rows, parseErrs, nil
+}
+
+// parseImportJSON decodes a JSON array one element at a time, so the upload
+// is never held in memory as a whole.
+func (h *SellerHandler) parseImportJSON(body io.Reader) ([]dto.ImportProductRow, []dto.ImportRowError, error) {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, nil, appErrors.NewAppError(response.CodeBadRequest, "request body must be a JSON array", err)
+	}
+
+	var (
+		rows      []dto.ImportProductRow
+		parseErrs []dto.ImportRowError
+	)
+	for n := 1; dec.More(); n++ {
+		if err := h.checkRowCount(n); err != nil {
+			return nil, nil, err
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, appErrors.NewAppError(response.CodeBadRequest, fmt.Sprintf("malformed JSON in row %d", n), err)
+		}
+		var req dto.CreateProductRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			parseErrs = append(parseErrs, dto.ImportRowError{Row: n, Error: "row does not match the product layout"})
+			continue
+		}
+		rows = append(rows, dto.ImportProductRow{Row: n, Product: req})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, appErrors.NewAppError(response.CodeBadRequest, "request body must be a JSON array", err)
+	}
+
+	return rows, parseErrs, nil
+}
@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"context"
+	"marketplace/pkg/dto"
+)
+
+type AdminUsecase interface {
+	// Impersonate issues a short-lived access token that acts as userID on
+	// behalf of adminID.
+	Impersonate(ctx context.Context, adminID, userID string) (*dto.ImpersonationResponse, error)
+	// RevokeSessions revokes every refresh token of userID and returns how
+	// many were active.
+	RevokeSessions(ctx context.Context, adminID, userID string) (int64, error)
+	ListAudit(ctx context.Context, filter dto.AuditFilter, limit, offset int) ([]dto.AuditEventResponse, error)
+}
@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"context"
+	errorsLib "errors"
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/adapter/postgres/audit"
+	"marketplace/internal/adapter/postgres/token"
+	"marketplace/internal/adapter/postgres/user"
+	"marketplace/pkg/clientinfo"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	"marketplace/pkg/errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	userTypeAdmin = "admin"
+
+	maxAuditLimit = 100
+
+	defaultImpersonationTTL = 15 * time.Minute
+	impersonationWindow     = time.Hour
+)
+
+var _ AdminUsecase = (*adminUsecase)(nil)
+
+type adminUsecase struct {
+	userRepo   user.UserRepository
+	tokenRepo  token.TokenRepository
+	auditRepo  audit.AuditRepository
+	jwtManager jwt.JWTManager
+	logger     *logrus.Logger
+	ttl        time.Duration
+	limiter    *windowLimiter
+}
+
+func NewAdminUsecase(userRepo user.UserRepository, tokenRepo token.TokenRepository, auditRepo audit.AuditRepository, jwtManager jwt.JWTManager, logger *logrus.Logger, cfg config.AdminConfig) *adminUsecase {
+	ttl := cfg.ImpersonationTTL
+	if ttl <= 0 {
+		ttl = defaultImpersonationTTL
+	}
+
+	return &adminUsecase{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		auditRepo:  auditRepo,
+		jwtManager: jwtManager,
+		logger:     logger,
+		ttl:        ttl,
+		limiter:    newWindowLimiter(cfg.ImpersonationsPerHour, impersonationWindow),
+	}
+}
+
+func (uc *adminUsecase) Impersonate(ctx context.Context, adminID, userID string) (*dto.ImpersonationResponse, error) {
+	if adminID == "" || userID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "impersonate",
+			"admin_id":  adminID,
+			"user_id":   userID,
+		}).Warn("Empty input")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty user id", nil)
+	}
+
+	target, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "user not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "impersonate",
+			"user_id":   userID,
+			"error":     err,
+		}).Warn("Failed get user")
+		return nil, errors.NewAppError("GET_ERR", "failed get user", err)
+	}
+
+	if target.UserType == userTypeAdmin {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "impersonate",
+			"admin_id":  adminID,
+			"user_id":   userID,
+		}).Warn("Attempt to impersonate an admin")
+		return nil, errors.NewAppError("FORBIDDEN", "admins cannot be impersonated", nil)
+	}
+
+	if !uc.limiter.allow(adminID, time.Now()) {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "impersonate",
+			"admin_id":  adminID,
+		}).Warn("Impersonation rate limit exceeded")
+		return nil, errors.NewAppError("RATE_LIMITED", "too many impersonation requests, retry later", nil)
+	}
+
+	token, expiresAt, err := uc.jwtManager.GenerateImpersonationToken(target, adminID, uc.ttl)
+	if err != nil {
+		return nil, errors.NewAppError("JWT_GENERATION", "failed to generate impersonation token", err)
+	}
+
+	client := clientinfo.FromContext(ctx)
+	uc.logger.WithFields(logrus.Fields{
+		"audit":           "impersonation",
+		"impersonator_id": adminID,
+		"user_id":         target.ID,
+		"user_type":       target.UserType,
+		"ip":              client.IP,
+		"user_agent":      client.UserAgent,
+		"expires_at":      expiresAt,
+	}).Info("Impersonation token issued")
+
+	return &dto.ImpersonationResponse{
+		AccessToken: token,
+		UserID:      target.ID,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (uc *adminUsecase) RevokeSessions(ctx context.Context, adminID, userID string) (int64, error) {
+	if adminID == "" || userID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "revoke_sessions",
+			"admin_id":  adminID,
+			"user_id":   userID,
+		}).Warn("Empty input")
+		return 0, errors.NewAppError("INVALID_INPUT", "empty user id", nil)
+	}
+
+	if _, err := uc.userRepo.GetByID(ctx, userID); err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return 0, errors.NewAppError("NOT_FOUND", "user not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "revoke_sessions",
+			"user_id":   userID,
+			"error":     err,
+		}).Warn("Failed get user")
+		return 0, errors.NewAppError("GET_ERR", "failed get user", err)
+	}
+
+	revoked, err := uc.tokenRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "revoke_sessions",
+			"user_id":   userID,
+			"error":     err,
+		}).Warn("Failed revoke sessions")
+		return 0, errors.NewAppError("UPDATE_FAIL", "failed to revoke sessions", err)
+	}
+
+	client := clientinfo.FromContext(ctx)
+	uc.logger.WithFields(logrus.Fields{
+		"audit":      "revoke_sessions",
+		"admin_id":   adminID,
+		"user_id":    userID,
+		"revoked":    revoked,
+		"ip":         client.IP,
+		"user_agent": client.UserAgent,
+	}).Info("User sessions revoked by admin")
+
+	return revoked, nil
+}
+
+func (uc *adminUsecase) ListAudit(ctx context.Context, filter dto.AuditFilter, limit, offset int) ([]dto.AuditEventResponse, error) {
+	if limit <= 0 || limit > maxAuditLimit || offset < 0 {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_audit",
+			"limit":     limit,
+			"offset":    offset,
+		}).Warn("Invalid pagination")
+		return nil, errors.NewAppError("INVALID_INPUT", "invalid limit or offset", nil)
+	}
+
+	events, err := uc.auditRepo.List(ctx, audit.ListFilter{
+		UserID: filter.UserID,
+		Event:  filter.Event,
+	}, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_audit",
+			"error":     err,
+		}).Warn("Failed list audit events")
+		return nil, errors.NewAppError("LIST_ERR", "failed list audit events", err)
+	}
+
+	resp := make([]dto.AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, dto.AuditEventResponse{
+			ID:        e.ID,
+			UserID:    e.UserID,
+			Event:     e.Event,
+			IPAddress: dto.NullString(e.IPAddress),
+			UserAgent: dto.NullString(e.UserAgent),
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"sync"
+	"time"
+)
+
+// windowLimiter allows at most limit events per key within a fixed window.
+type windowLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+func newWindowLimiter(limit int, window time.Duration) *windowLimiter {
+	return &windowLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// allow records an event for key and reports whether it fits the limit. A
+// non-positive limit disables limiting.
+func (l *windowLimiter) allow(key string, now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	recent := l.hits[key][:0]
+	for _, t := range l.hits[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.hits[key] = recent
+		return false
+	}
+
+	l.hits[key] = append(recent, now)
+	return true
+}
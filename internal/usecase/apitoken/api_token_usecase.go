@@ -0,0 +1,13 @@
+package apitoken
+
+import (
+	"context"
+	"marketplace/pkg/dto"
+)
+
+type APITokenUsecase interface {
+	Generate(ctx context.Context, sellerID string) (*dto.APITokenResponse, error)
+	Revoke(ctx context.Context, sellerID string) error
+	// Authenticate resolves a plaintext API token to its seller id.
+	Authenticate(ctx context.Context, token string) (string, error)
+}
@@ -0,0 +1,131 @@
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	errorsLib "errors"
+	"marketplace/internal/adapter/postgres/apitoken"
+	"marketplace/internal/entity"
+	"marketplace/pkg/dto"
+	"marketplace/pkg/errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tokenPrefix = "mkp_"
+	tokenBytes  = 32
+)
+
+var _ APITokenUsecase = (*apiTokenUsecase)(nil)
+
+type apiTokenUsecase struct {
+	adapter apitoken.APITokenRepository
+	logger  *logrus.Logger
+}
+
+func NewAPITokenUsecase(adapter apitoken.APITokenRepository, logger *logrus.Logger) *apiTokenUsecase {
+	return &apiTokenUsecase{
+		adapter: adapter,
+		logger:  logger,
+	}
+}
+
+func (uc *apiTokenUsecase) Generate(ctx context.Context, sellerID string) (*dto.APITokenResponse, error) {
+	if sellerID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "generate",
+		}).Warn("Empty seller id")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "generate",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Error("Failed generate random token")
+		return nil, errors.NewAppError("TOKEN_GENERATION", "failed generate api token", err)
+	}
+	plain := tokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	token := &entity.SellerAPIToken{
+		SellerID:  sellerID,
+		TokenHash: hashToken(plain),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := uc.adapter.Upsert(ctx, token); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "generate",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Warn("Failed store api token")
+		return nil, errors.NewAppError("CREATE_ERR", "failed store api token", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "generate",
+		"seller_id": sellerID,
+	}).Info("API token generated")
+
+	return &dto.APITokenResponse{Token: plain, CreatedAt: now}, nil
+}
+
+func (uc *apiTokenUsecase) Revoke(ctx context.Context, sellerID string) error {
+	if err := uc.adapter.Revoke(ctx, sellerID); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "revoke",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Warn("Failed revoke api token")
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "api token not found", err)
+		}
+		return errors.NewAppError("UPDATE_ERR", "failed revoke api token", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "revoke",
+		"seller_id": sellerID,
+	}).Info("API token revoked")
+
+	return nil
+}
+
+func (uc *apiTokenUsecase) Authenticate(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", errors.NewAppError("INVALID_TOKEN", "missing api token", nil)
+	}
+
+	stored, err := uc.adapter.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return "", errors.NewAppError("INVALID_TOKEN", "invalid api token", nil)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "authenticate",
+			"error":     err,
+		}).Warn("Failed get api token")
+		return "", errors.NewAppError("GET_ERR", "failed check api token", err)
+	}
+
+	if stored.IsRevoked {
+		return "", errors.NewAppError("INVALID_TOKEN", "api token revoked", nil)
+	}
+
+	return stored.SellerID, nil
+}
+
+// hashToken returns the value stored at rest for a plaintext API token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
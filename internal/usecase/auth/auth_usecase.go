@@ -8,7 +8,22 @@ import (
 type AuthUsecase interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error)
 	Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error)
+	// Refresh issues a new access token and rotates the refresh token; the
+	// presented one is revoked.
+	Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error)
 	UpdateAuth(ctx context.Context, tokenString, userID string, req dto.UpdateAuthRequest) error
+	// VerifyEmail consumes a token mailed at registration.
+	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
+	// GetProfile returns a *dto.CustomerProfileResponse or a
+	// *dto.SellerProfileResponse depending on userType.
+	GetProfile(ctx context.Context, userID, userType string) (any, error)
 	UpdateProfile(ctx context.Context, userID string, userType string, payload any) error
+	// UpdateShippingAddress replaces a customer's shipping address.
+	UpdateShippingAddress(ctx context.Context, userID string, req dto.ShippingAddress) error
 	DeleteUser(ctx context.Context, userID string) error
+	ListSessions(ctx context.Context, userID string) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	// SessionStatus reports whether a session is valid, revoked or expired.
+	// Admins may look up any session, other users only their own.
+	SessionStatus(ctx context.Context, userID, userType, sessionID string) (*dto.SessionStatusResponse, error)
 }
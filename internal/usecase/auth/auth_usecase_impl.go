@@ -2,18 +2,29 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"marketplace/internal/adapter/bcrypt"
 	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/adapter/mailer"
+	"marketplace/internal/adapter/postgres/audit"
 	"marketplace/internal/adapter/postgres/customer"
+	"marketplace/internal/adapter/postgres/passwordhistory"
 	"marketplace/internal/adapter/postgres/seller"
 	"marketplace/internal/adapter/postgres/token"
 	"marketplace/internal/adapter/postgres/user"
+	"marketplace/internal/adapter/postgres/verification"
 	"marketplace/internal/entity"
+	"marketplace/pkg/clientinfo"
+	"marketplace/pkg/config"
 	"marketplace/pkg/dto"
 	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
 	"strings"
 	"time"
 
@@ -22,15 +33,28 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// verificationTokenBytes is the entropy of email verification tokens.
+const verificationTokenBytes = 32
+
+var _ AuthUsecase = (*authUsecase)(nil)
+
 type authUsecase struct {
 	userRepo     user.UserRepository
 	customerRepo customer.CustomerRepository
 	sellerRepo   seller.SellerRepository
 	tokenRepo    token.TokenRepository
+	auditRepo    audit.AuditRepository
+	historyRepo  passwordhistory.PasswordHistoryRepository
+	verifyRepo   verification.VerificationRepository
+	mailer       mailer.Mailer
+	txManager    adapter.TxManager
 	jwtManager   jwt.JWTManager
 	hashManager  bcrypt.Hasher
 	validator    *validator.Validate
 	logger       *logrus.Logger
+	cfg          config.AuthConfig
+	// deletePolicy is config.DeleteSoft or config.DeleteHard.
+	deletePolicy string
 }
 
 func NewAuthUsecase(
@@ -38,23 +62,40 @@ func NewAuthUsecase(
 	customerRepo customer.CustomerRepository,
 	sellerRepo seller.SellerRepository,
 	tokenRepo token.TokenRepository,
+	auditRepo audit.AuditRepository,
+	historyRepo passwordhistory.PasswordHistoryRepository,
+	verifyRepo verification.VerificationRepository,
+	mailer mailer.Mailer,
+	txManager adapter.TxManager,
 	jwtManager jwt.JWTManager,
 	hashManager bcrypt.Hasher,
 	logger *logrus.Logger,
+	cfg config.AuthConfig,
+	deletePolicy string,
 ) *authUsecase {
 	return &authUsecase{
 		userRepo:     userRepo,
 		customerRepo: customerRepo,
 		sellerRepo:   sellerRepo,
 		tokenRepo:    tokenRepo,
+		auditRepo:    auditRepo,
+		historyRepo:  historyRepo,
+		verifyRepo:   verifyRepo,
+		mailer:       mailer,
+		txManager:    txManager,
 		jwtManager:   jwtManager,
 		hashManager:  hashManager,
 		validator:    validator.New(),
 		logger:       logger,
+		cfg:          cfg,
+		deletePolicy: deletePolicy,
 	}
 }
 
 func (uc *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error) {
+	if err := uc.checkIdentifierLength(req.Email, req.Username); err != nil {
+		return nil, err
+	}
 	if err := uc.validator.Struct(req); err != nil {
 		return nil, appErrors.NewAppError("VALIDATION", "invalid registration data", err)
 	}
@@ -65,32 +106,20 @@ func (uc *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*
 		return nil, appErrors.NewAppError("INVALID_TYPE", "unsupported user_type", nil)
 	}
 
-	// Проверка уникальности
-	var existingErr error
-	switch userType {
-	case "customer":
-		_, existingErr = uc.customerRepo.GetByEmail(ctx, req.Email)
-		if existingErr == nil {
-			return nil, appErrors.NewAppError("DUPLICATE", "email already exists", nil)
-		}
-		_, existingErr = uc.customerRepo.GetByUsername(ctx, req.Username)
-		if existingErr == nil {
-			return nil, appErrors.NewAppError("DUPLICATE", "username already exists", nil)
-		}
-	case "seller":
-		_, existingErr = uc.sellerRepo.GetByEmail(ctx, req.Email)
-		if existingErr == nil {
-			return nil, appErrors.NewAppError("DUPLICATE", "email already exists", nil)
+	// Проверка уникальности: email и username уникальны среди всех users
+	for _, field := range []struct{ column, value string }{
+		{"email", req.Email},
+		{"username", req.Username},
+	} {
+		exists, err := uc.userRepo.Exists(ctx, field.column, field.value)
+		if err != nil {
+			uc.logger.WithError(err).Error("failed to check uniqueness")
+			return nil, appErrors.NewAppError("REPO", "uniqueness check failed", err)
 		}
-		_, existingErr = uc.sellerRepo.GetByUsername(ctx, req.Username)
-		if existingErr == nil {
-			return nil, appErrors.NewAppError("DUPLICATE", "username already exists", nil)
+		if exists {
+			return nil, appErrors.NewAppError("DUPLICATE", field.column+" already exists", nil)
 		}
 	}
-	if existingErr != nil && !errors.Is(existingErr, appErrors.ErrNotFound) {
-		uc.logger.WithError(existingErr).Error("failed to check uniqueness")
-		return nil, appErrors.NewAppError("REPO", "uniqueness check failed", existingErr)
-	}
 
 	hashed, err := uc.hashManager.GenerateHashPassword(req.Password)
 	if err != nil {
@@ -109,37 +138,74 @@ func (uc *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*
 		UpdatedAt:    now,
 	}
 
-	if err := uc.userRepo.Create(ctx, u); err != nil {
-		uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Error("user create failed")
-		return nil, appErrors.NewAppError("USER_CREATE_FAIL", "failed to create user", err)
-	}
+	// The user and its refresh token are stored in one transaction, so a
+	// failed token store does not leave a user behind.
+	var access, refresh, verifyToken string
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, u); err != nil {
+			uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Error("user create failed")
+			return appErrors.NewAppError("USER_CREATE_FAIL", "failed to create user", err)
+		}
 
-	access, err := uc.jwtManager.GenerateAccessToken(u)
+		var err error
+		if verifyToken, err = uc.issueVerification(ctx, u.ID, now); err != nil {
+			return err
+		}
+
+		if uc.cfg.RequireEmailVerification {
+			return nil
+		}
+
+		access, err = uc.jwtManager.GenerateAccessToken(u)
+		if err != nil {
+			return appErrors.NewAppError("JWT_GENERATION", "failed to generate access token", err)
+		}
+
+		refresh, err = uc.jwtManager.GenerateRefreshToken(ctx, u)
+		if err != nil {
+			return appErrors.NewAppError("JWT_GENERATION", "failed to generate refresh token", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, appErrors.NewAppError("JWT_GENERATION", "failed to generate access token", err)
+		if _, ok := err.(*appErrors.AppError); !ok {
+			return nil, appErrors.NewAppError("USER_CREATE_FAIL", "failed to create user", err)
+		}
+		return nil, err
 	}
+	uc.recordAudit(ctx, u.ID, entity.AuditRegister)
 
-	refresh, err := uc.jwtManager.GenerateRefreshToken(ctx, u)
-	if err != nil {
-		return nil, appErrors.NewAppError("JWT_GENERATION", "failed to generate refresh token", err)
+	// The account exists either way; a lost email only delays verification.
+	if err := uc.mailer.SendVerification(ctx, u.Email, verifyToken); err != nil {
+		uc.logger.WithError(err).WithField("user_id", u.ID).Warn("failed to send verification email")
+	}
+
+	if uc.cfg.RequireEmailVerification {
+		uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Info("user registered, verification required")
+		return &dto.AuthResponse{
+			Status: dto.AuthStatusVerificationRequired,
+			User: &dto.UserInfo{
+				ID:       u.ID,
+				Username: u.Username,
+				Email:    u.Email,
+				UserType: u.UserType,
+			},
+		}, nil
 	}
 
 	uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Info("user registered")
 
-	return &dto.AuthResponse{AccessToken: access, RefreshToken: refresh}, nil
+	return uc.tokenResponse(access, refresh), nil
 }
 
 func (uc *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+	if err := uc.checkIdentifierLength(req.Email, req.Username); err != nil {
+		return nil, err
+	}
 	if err := uc.validator.Struct(req); err != nil {
 		return nil, appErrors.NewAppError("VALIDATION", "invalid login data", err)
 	}
 
-	userType := strings.ToLower(strings.TrimSpace(req.UserType))
-	if userType != "customer" && userType != "seller" {
-		uc.logger.WithField("user_type", req.UserType).Warn("invalid user_type")
-		return nil, appErrors.NewAppError("INVALID_TYPE", "unsupported user_type", nil)
-	}
-
 	username := strings.TrimSpace(req.Username)
 	email := strings.TrimSpace(req.Email)
 	if username != "" && email != "" {
@@ -157,8 +223,20 @@ func (uc *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Au
 	}
 
 	var u entity.User
+	var passwordHash string
 	var err error
 
+	userType := strings.ToLower(strings.TrimSpace(req.UserType))
+	if userType == "" {
+		if userType, err = uc.inferUserType(ctx, lookupBy, identifier); err != nil {
+			return nil, err
+		}
+	}
+	if userType != "customer" && userType != "seller" && userType != "admin" {
+		uc.logger.WithField("user_type", req.UserType).Warn("invalid user_type")
+		return nil, appErrors.NewAppError("INVALID_TYPE", "unsupported user_type", nil)
+	}
+
 	switch userType {
 	case "customer":
 		var c *entity.CustomerProfile
@@ -177,6 +255,7 @@ func (uc *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Au
 			return nil, appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
 		}
 		u = entity.User{ID: c.ID, UserType: userType, Username: c.Username, Email: c.Email, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt}
+		passwordHash = c.PasswordHash
 
 	case "seller":
 		var s *entity.SellerProfile
@@ -195,6 +274,41 @@ func (uc *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Au
 			return nil, appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
 		}
 		u = entity.User{ID: s.ID, UserType: userType, Username: s.Username, Email: s.Email, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt}
+		passwordHash = s.PasswordHash
+
+	case "admin":
+		// Admins have no profile table; they are plain users rows created
+		// outside the API.
+		var a *entity.User
+		if lookupBy == "email" {
+			a, err = uc.userRepo.GetByEmail(ctx, identifier)
+		} else {
+			a, err = uc.userRepo.GetByUsername(ctx, identifier)
+		}
+		if err != nil {
+			if errors.Is(err, appErrors.ErrNotFound) {
+				return nil, appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
+			}
+			return nil, appErrors.NewAppError("REPO", "failed to fetch user", err)
+		}
+		if a.UserType != userType {
+			return nil, appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
+		}
+		if err = uc.hashManager.CompareHashPassword(a.PasswordHash, req.Password); err != nil {
+			return nil, appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
+		}
+		u = *a
+		passwordHash = a.PasswordHash
+	}
+
+	if userType != "admin" {
+		if err := uc.checkVerified(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if uc.hashManager.NeedsRehash(passwordHash) {
+		uc.rehashPassword(ctx, &u, req.Password)
 	}
 
 	access, err := uc.jwtManager.GenerateAccessToken(&u)
@@ -204,18 +318,192 @@ func (uc *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Au
 
 	refresh, err := uc.jwtManager.GenerateRefreshToken(ctx, &u)
 	if err != nil {
-		return nil, appErrors.NewAppError("JWT_GENERATION", "failed to generate refresh token", err)
+		if !uc.canDegrade(err) {
+			uc.logger.WithFields(logrus.Fields{
+				"user_id": u.ID,
+				"mode":    "fail",
+				"error":   err,
+			}).Error("login failed: refresh token not issued")
+			return nil, appErrors.NewAppError("JWT_GENERATION", "failed to generate refresh token", err)
+		}
+
+		uc.logger.WithFields(logrus.Fields{
+			"user_id": u.ID,
+			"mode":    "degraded",
+			"error":   err,
+		}).Warn("token store unavailable, login without refresh token")
+		uc.recordAudit(ctx, u.ID, entity.AuditLogin)
+		resp := uc.tokenResponse(access, "")
+		resp.Warning = "refresh token unavailable, log in again when the access token expires"
+		return resp, nil
 	}
 
 	uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Info("user logged in")
+	uc.recordAudit(ctx, u.ID, entity.AuditLogin)
+
+	return uc.tokenResponse(access, refresh), nil
+}
+
+// VerifyEmail consumes a verification token and marks its user verified.
+func (uc *authUsecase) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+	if err := uc.validator.Struct(req); err != nil {
+		return appErrors.NewAppError("VALIDATION", "invalid verification data", err)
+	}
+
+	var userID string
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if userID, err = uc.verifyRepo.Consume(ctx, hashVerificationToken(req.Token), time.Now()); err != nil {
+			return err
+		}
+		return uc.userRepo.MarkVerified(ctx, userID)
+	})
+	if err != nil {
+		if _, ok := err.(*appErrors.AppError); !ok {
+			return appErrors.NewAppError("REPO", "failed to verify email", err)
+		}
+		return err
+	}
+
+	uc.logger.WithField("user_id", userID).Info("email verified")
+	return nil
+}
+
+// issueVerification stores a new verification token for userID and returns
+// the plaintext to mail.
+func (uc *authUsecase) issueVerification(ctx context.Context, userID string, now time.Time) (string, error) {
+	raw := make([]byte, verificationTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", appErrors.NewAppError("TOKEN_GENERATION", "failed to generate verification token", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := uc.verifyRepo.Create(ctx, &entity.EmailVerification{
+		TokenHash: hashVerificationToken(token),
+		UserID:    userID,
+		ExpiresAt: now.Add(uc.cfg.VerificationTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// checkVerified enforces AuthConfig.BlockUnverifiedLogin.
+func (uc *authUsecase) checkVerified(ctx context.Context, userID string) error {
+	if !uc.cfg.BlockUnverifiedLogin {
+		return nil
+	}
 
-	return &dto.AuthResponse{AccessToken: access, RefreshToken: refresh}, nil
+	u, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return appErrors.NewAppError("REPO", "failed to fetch user", err)
+	}
+	if !u.IsVerified {
+		return appErrors.NewAppError("FORBIDDEN", "email is not verified", nil)
+	}
+	return nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenResponse wraps issued tokens with the access token lifetime.
+func (uc *authUsecase) tokenResponse(access, refresh string) *dto.AuthResponse {
+	return &dto.AuthResponse{
+		AccessToken:          access,
+		RefreshToken:         refresh,
+		AccessTokenExpiresIn: int64(uc.jwtManager.AccessTokenTTL().Seconds()),
+	}
+}
+
+// canDegrade reports whether a refresh token failure may be answered with
+// an access token alone: the option is on and the token store failed for a
+// reason other than the request itself going away.
+func (uc *authUsecase) canDegrade(err error) bool {
+	if !uc.cfg.DegradeOnTokenStoreError || appErrors.ContextError(err) != nil {
+		return false
+	}
+	var appErr *appErrors.AppError
+	return errors.As(err, &appErr) && appErr.Code() == jwt.CodeTokenStore
+}
+
+func (uc *authUsecase) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
+	if err := uc.validator.Struct(req); err != nil {
+		return nil, appErrors.NewAppError("VALIDATION", "invalid refresh data", err)
+	}
+
+	if err := uc.jwtManager.ValidateRefreshToken(ctx, req.RefreshToken); err != nil {
+		return nil, appErrors.NewAppError("INVALID_TOKEN", "invalid refresh token", err)
+	}
+
+	stored, err := uc.tokenRepo.GetByToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, appErrors.NewAppError("INVALID_TOKEN", "invalid refresh token", err)
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrNotFound) {
+			return nil, appErrors.NewAppError("INVALID_TOKEN", "invalid refresh token", err)
+		}
+		return nil, appErrors.NewAppError("REPO", "failed to load user", err)
+	}
+
+	access, err := uc.jwtManager.GenerateAccessToken(u)
+	if err != nil {
+		return nil, appErrors.NewAppError("JWT_GENERATION", "failed to generate access token", err)
+	}
+
+	// Revoking the presented token and storing its successor in one
+	// transaction keeps the user logged in if the store fails.
+	var refresh string
+	var reused bool
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		revoked, err := uc.tokenRepo.RevokeToken(ctx, stored.ID)
+		if err != nil {
+			return appErrors.NewAppError("REPO", "failed to revoke refresh token", err)
+		}
+		if !revoked {
+			// A concurrent refresh already rotated this token.
+			reused = true
+			return appErrors.NewAppError("INVALID_TOKEN", "refresh token already used", nil)
+		}
+
+		refresh, err = uc.jwtManager.GenerateRefreshToken(ctx, u)
+		if err != nil {
+			return appErrors.NewAppError("JWT_GENERATION", "failed to generate refresh token", err)
+		}
+		return nil
+	})
+	if reused {
+		if _, revokeErr := uc.tokenRepo.RevokeAllForUser(ctx, u.ID); revokeErr != nil {
+			uc.logger.WithError(revokeErr).WithField("user_id", u.ID).Error("failed to revoke sessions after refresh token reuse")
+		}
+		uc.logger.WithField("user_id", u.ID).Warn("refresh token reused, all sessions revoked")
+	}
+	if err != nil {
+		if _, ok := err.(*appErrors.AppError); !ok {
+			return nil, appErrors.NewAppError("REPO", "failed to rotate refresh token", err)
+		}
+		return nil, err
+	}
+
+	uc.logger.WithFields(logrus.Fields{"user_id": u.ID, "type": u.UserType}).Info("tokens refreshed")
+
+	return uc.tokenResponse(access, refresh), nil
 }
 
 func (uc *authUsecase) UpdateAuth(ctx context.Context, tokenString, userID string, req dto.UpdateAuthRequest) error {
 	if err := uc.validator.Struct(req); err != nil {
 		return appErrors.NewAppError("VALIDATION", "invalid update data", err)
 	}
+	if req.IsEmpty() {
+		return appErrors.NewAppError("INVALID_INPUT", "nothing to update", nil)
+	}
 
 	if err := uc.jwtManager.ValidateRefreshToken(ctx, tokenString); err != nil {
 		return appErrors.NewAppError("INVALID_TOKEN", "invalid refresh token", err)
@@ -234,6 +522,9 @@ func (uc *authUsecase) UpdateAuth(ctx context.Context, tokenString, userID strin
 		if err := uc.hashManager.CompareHashPassword(userByID.PasswordHash, req.OldPassword); err != nil {
 			return appErrors.NewAppError("INVALID_CREDENTIALS", "old password incorrect", nil)
 		}
+		if err := uc.checkPasswordReuse(ctx, userByID, req.NewPassword); err != nil {
+			return err
+		}
 		newHash, err = uc.hashManager.GenerateHashPassword(req.NewPassword)
 		if err != nil {
 			return appErrors.NewAppError("HASHING", "failed to hash new password", err)
@@ -254,6 +545,13 @@ func (uc *authUsecase) UpdateAuth(ctx context.Context, tokenString, userID strin
 		return appErrors.NewAppError("UPDATE_FAILED", "failed to update user", err)
 	}
 
+	if req.NewPassword != "" {
+		uc.rememberPassword(ctx, userID, userByID.PasswordHash)
+		uc.recordAudit(ctx, userID, entity.AuditPasswordChange)
+	} else {
+		uc.recordAudit(ctx, userID, entity.AuditAuthChange)
+	}
+
 	if err := uc.revokeRefreshToken(ctx, userID); err != nil {
 		uc.logger.WithField("user_id", userID).Warn("failed to revoke token after update")
 	}
@@ -261,6 +559,38 @@ func (uc *authUsecase) UpdateAuth(ctx context.Context, tokenString, userID strin
 	return nil
 }
 
+func (uc *authUsecase) GetProfile(ctx context.Context, userID, userType string) (any, error) {
+	switch strings.ToLower(strings.TrimSpace(userType)) {
+	case "customer":
+		c, err := uc.customerRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, profileLookupError(err)
+		}
+		return toCustomerProfileResponse(c), nil
+
+	case "seller":
+		s, err := uc.sellerRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, profileLookupError(err)
+		}
+		return toSellerProfileResponse(s), nil
+
+	default:
+		return nil, appErrors.NewAppError("INVALID_TYPE", "unsupported user type", nil)
+	}
+}
+
+// profileLookupError keeps context and not-found errors and wraps the rest.
+func profileLookupError(err error) error {
+	if appErrors.ContextError(err) != nil {
+		return err
+	}
+	if errors.Is(err, appErrors.ErrNotFound) {
+		return appErrors.NewAppError("NOT_FOUND", "profile not found", err)
+	}
+	return appErrors.NewAppError("REPO", "failed to load profile", err)
+}
+
 func (uc *authUsecase) UpdateProfile(ctx context.Context, userID string, userType string, payload interface{}) error {
 	userType = strings.ToLower(strings.TrimSpace(userType))
 	now := time.Now()
@@ -274,22 +604,39 @@ func (uc *authUsecase) UpdateProfile(ctx context.Context, userID string, userTyp
 		if err := uc.validator.Struct(req); err != nil {
 			return appErrors.NewAppError("VALIDATION", "invalid customer profile data", err)
 		}
+		if req.IsEmpty() {
+			return appErrors.NewAppError("INVALID_INPUT", "nothing to update", nil)
+		}
 
 		profile := &entity.CustomerProfile{
 			User:      entity.User{ID: userID, UpdatedAt: now},
 			Phone:     sql.NullString{String: req.Phone, Valid: req.Phone != ""},
+			PhoneKey:  sql.NullString{String: req.Phone, Valid: req.Phone != "" && uc.cfg.UniquePhone},
 			FirstName: sql.NullString{String: req.FirstName, Valid: req.FirstName != ""},
 			LastName:  sql.NullString{String: req.LastName, Valid: req.LastName != ""},
 			Address:   sql.NullString{String: req.Address, Valid: req.Address != ""},
 		}
+		switch {
+		case req.ShippingAddress != nil:
+			setShippingAddress(profile, *req.ShippingAddress, req.Address)
+		case req.Address != "":
+			// Clients that only know the free-text field still move the
+			// structured address along, as the migration did.
+			profile.AddressLine1 = profile.Address
+		}
+		if err := uc.checkPhone(ctx, req.Phone, userID); err != nil {
+			return err
+		}
 		if req.DateBirth != "" {
-			dt, err := time.Parse("2006-01-02", req.DateBirth)
+			dt, err := time.Parse(dto.DateLayout, req.DateBirth)
 			if err != nil {
 				return appErrors.NewAppError("INVALID_FORMAT", "invalid date format", err)
 			}
 			profile.DateBirth = sql.NullTime{Time: dt, Valid: true}
 		}
-		return uc.customerRepo.UpdateProfile(ctx, profile)
+		if err := uc.customerRepo.UpdateProfile(ctx, profile); err != nil {
+			return err
+		}
 
 	case "seller":
 		req, ok := payload.(dto.SellerProfileRequest)
@@ -299,17 +646,71 @@ func (uc *authUsecase) UpdateProfile(ctx context.Context, userID string, userTyp
 		if err := uc.validator.Struct(req); err != nil {
 			return appErrors.NewAppError("VALIDATION", "invalid seller profile data", err)
 		}
+		if req.IsEmpty() {
+			return appErrors.NewAppError("INVALID_INPUT", "nothing to update", nil)
+		}
 
 		profile := &entity.SellerProfile{
 			User:        entity.User{ID: userID, UpdatedAt: now},
 			CompanyName: sql.NullString{String: req.CompanyName, Valid: req.CompanyName != ""},
 			Rating:      sql.NullFloat64{Float64: req.Rating, Valid: true},
 		}
-		return uc.sellerRepo.UpdateProfile(ctx, profile)
+		if err := uc.sellerRepo.UpdateProfile(ctx, profile); err != nil {
+			return err
+		}
 
 	default:
 		return appErrors.NewAppError("INVALID_TYPE", "unsupported user type", nil)
 	}
+
+	uc.recordAudit(ctx, userID, entity.AuditProfileChange)
+	return nil
+}
+
+// checkPhone enforces AuthConfig.UniquePhone for a phone the customer
+// userID wants to use.
+func (uc *authUsecase) checkPhone(ctx context.Context, phone, userID string) error {
+	if !uc.cfg.UniquePhone || phone == "" {
+		return nil
+	}
+
+	taken, err := uc.customerRepo.PhoneTaken(ctx, phone, userID)
+	if err != nil {
+		uc.logger.WithError(err).Error("failed to check phone uniqueness")
+		return appErrors.NewAppError("REPO", "uniqueness check failed", err)
+	}
+	if taken {
+		return appErrors.NewAppError("DUPLICATE", "phone already exists", nil)
+	}
+	return nil
+}
+
+func (uc *authUsecase) UpdateShippingAddress(ctx context.Context, userID string, req dto.ShippingAddress) error {
+	if err := uc.validator.Struct(req); err != nil {
+		return appErrors.NewAppError("VALIDATION", "invalid shipping address", err)
+	}
+
+	profile := &entity.CustomerProfile{User: entity.User{ID: userID, UpdatedAt: time.Now()}}
+	setShippingAddress(profile, req, "")
+	if err := uc.customerRepo.UpdateAddress(ctx, profile); err != nil {
+		return err
+	}
+
+	uc.recordAudit(ctx, userID, entity.AuditProfileChange)
+	return nil
+}
+
+// setShippingAddress copies addr onto profile. The free-text address is set
+// to freeText, or to the formatted address when freeText is empty.
+func setShippingAddress(profile *entity.CustomerProfile, addr dto.ShippingAddress, freeText string) {
+	if freeText == "" {
+		freeText = addr.String()
+	}
+	profile.Address = sql.NullString{String: freeText, Valid: true}
+	profile.AddressLine1 = sql.NullString{String: addr.Line1, Valid: true}
+	profile.AddressCity = sql.NullString{String: addr.City, Valid: true}
+	profile.AddressPostalCode = sql.NullString{String: addr.PostalCode, Valid: true}
+	profile.AddressCountry = sql.NullString{String: addr.Country, Valid: true}
 }
 
 func (uc *authUsecase) DeleteUser(ctx context.Context, userID string) error {
@@ -317,26 +718,261 @@ func (uc *authUsecase) DeleteUser(ctx context.Context, userID string) error {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
-	if err := uc.userRepo.Delete(ctx, userID); err != nil {
+	deleteFn := uc.userRepo.Delete
+	if uc.deletePolicy == config.DeleteSoft {
+		deleteFn = uc.userRepo.SoftDelete
+	}
+	if err := deleteFn(ctx, userID); err != nil {
 		if errors.Is(err, appErrors.ErrNotFound) {
 			return appErrors.NewAppError("NOT_FOUND", "user not found", err)
 		}
 		return appErrors.NewAppError("DELETE_FAIL", "failed to delete user", err)
 	}
 
-	uc.logger.WithField("user_id", userID).Info("user deleted")
+	uc.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"policy":  uc.deletePolicy,
+	}).Info("user deleted")
+	uc.recordAudit(ctx, userID, entity.AuditDelete)
 	return nil
 }
 
-func (uc *authUsecase) revokeRefreshToken(ctx context.Context, userID string) error {
-	t, err := uc.tokenRepo.GetRefreshTokenByUserID(ctx, userID)
+func (uc *authUsecase) ListSessions(ctx context.Context, userID string) ([]dto.SessionResponse, error) {
+	sessions, err := uc.tokenRepo.ListSessionsByUserID(ctx, userID)
 	if err != nil {
+		uc.logger.WithError(err).WithField("user_id", userID).Error("failed to list sessions")
+		return nil, appErrors.NewAppError("REPO", "failed to list sessions", err)
+	}
+
+	resp := make([]dto.SessionResponse, 0, len(sessions))
+	for _, t := range sessions {
+		session := dto.SessionResponse{
+			ID:        t.ID,
+			UserAgent: dto.NullString(t.UserAgent),
+			IPAddress: dto.NullString(t.IPAddress),
+			CreatedAt: t.CreatedAt,
+			ExpiresAt: t.ExpiresAt,
+		}
+		if t.LastUsedAt.Valid {
+			lastUsed := t.LastUsedAt.Time
+			session.LastUsedAt = &lastUsed
+		}
+		resp = append(resp, session)
+	}
+
+	return resp, nil
+}
+
+func (uc *authUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		return appErrors.NewAppError("VALIDATION", "session id is required", nil)
+	}
+
+	if err := uc.tokenRepo.RevokeSession(ctx, userID, sessionID); err != nil {
 		if errors.Is(err, appErrors.ErrNotFound) {
-			return nil
+			return appErrors.NewAppError("NOT_FOUND", "session not found", err)
 		}
-		return err
+		uc.logger.WithError(err).WithFields(logrus.Fields{"user_id": userID, "session_id": sessionID}).Error("failed to revoke session")
+		return appErrors.NewAppError("REPO", "failed to revoke session", err)
 	}
-	t.IsRevoked = true
-	t.UpdatedAt = time.Now()
-	return uc.tokenRepo.UpsertRefreshToken(ctx, t)
+
+	uc.logger.WithFields(logrus.Fields{"user_id": userID, "session_id": sessionID}).Info("session revoked")
+	uc.recordAudit(ctx, userID, entity.AuditLogout)
+	return nil
+}
+
+func (uc *authUsecase) SessionStatus(ctx context.Context, userID, userType, sessionID string) (*dto.SessionStatusResponse, error) {
+	if sessionID == "" {
+		return nil, appErrors.NewAppError("VALIDATION", "session id is required", nil)
+	}
+
+	t, err := uc.tokenRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrNotFound) {
+			return nil, appErrors.NewAppError("NOT_FOUND", "session not found", err)
+		}
+		uc.logger.WithError(err).WithFields(logrus.Fields{"user_id": userID, "session_id": sessionID}).Error("failed to get session")
+		return nil, appErrors.NewAppError("REPO", "failed to get session", err)
+	}
+	// Another user's session is reported as missing so ids cannot be probed.
+	if t.UserID != userID && userType != "admin" {
+		return nil, appErrors.NewAppError("NOT_FOUND", "session not found", nil)
+	}
+
+	resp := &dto.SessionStatusResponse{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Status:    dto.SessionStatusValid,
+		ExpiresAt: t.ExpiresAt,
+	}
+	switch {
+	case t.IsRevoked:
+		resp.Status = dto.SessionStatusRevoked
+	case time.Now().After(t.ExpiresAt):
+		resp.Status = dto.SessionStatusExpired
+	}
+	if t.LastUsedAt.Valid {
+		lastUsed := t.LastUsedAt.Time
+		resp.LastUsedAt = &lastUsed
+	}
+
+	return resp, nil
+}
+
+// checkIdentifierLength rejects oversized emails and usernames before they
+// reach the validator, the database or bcrypt.
+func (uc *authUsecase) checkIdentifierLength(identifiers ...string) error {
+	limit := uc.cfg.MaxIdentifierLength
+	if limit <= 0 {
+		return nil
+	}
+
+	for _, identifier := range identifiers {
+		if len(identifier) > limit {
+			return appErrors.NewAppError("VALIDATION", fmt.Sprintf("identifier exceeds %d characters", limit), nil)
+		}
+	}
+
+	return nil
+}
+
+// inferUserType resolves the user_type of a login that omitted it. Unknown
+// users get INVALID_CREDENTIALS so the lookup does not reveal which accounts
+// exist.
+func (uc *authUsecase) inferUserType(ctx context.Context, lookupBy, identifier string) (string, error) {
+	if !uc.cfg.InferUserType {
+		return "", appErrors.NewAppError("VALIDATION", "user_type is required", nil)
+	}
+
+	var u *entity.User
+	var err error
+	if lookupBy == "email" {
+		u, err = uc.userRepo.GetByEmail(ctx, identifier)
+	} else {
+		u, err = uc.userRepo.GetByUsername(ctx, identifier)
+	}
+	if err != nil {
+		if errors.Is(err, appErrors.ErrNotFound) {
+			return "", appErrors.NewAppError("INVALID_CREDENTIALS", "invalid credentials", nil)
+		}
+		return "", appErrors.NewAppError("REPO", "failed to fetch user", err)
+	}
+
+	return strings.ToLower(u.UserType), nil
+}
+
+// rehashPassword upgrades a weak password hash after a successful login.
+// Failures are logged and never block the login itself.
+func (uc *authUsecase) rehashPassword(ctx context.Context, u *entity.User, password string) {
+	newHash, err := uc.hashManager.Rehash(password)
+	if err != nil {
+		uc.logger.WithError(err).WithField("user_id", u.ID).Warn("failed to rehash password")
+		return
+	}
+
+	if err := uc.userRepo.UpdateAuth(ctx, u.ID, u.Username, u.Email, newHash); err != nil {
+		uc.logger.WithError(err).WithField("user_id", u.ID).Warn("failed to store rehashed password")
+	}
+}
+
+func toCustomerProfileResponse(c *entity.CustomerProfile) *dto.CustomerProfileResponse {
+	resp := &dto.CustomerProfileResponse{
+		ID:        c.ID,
+		Username:  c.Username,
+		Email:     c.Email,
+		Phone:     dto.NullString(c.Phone),
+		FirstName: dto.NullString(c.FirstName),
+		LastName:  dto.NullString(c.LastName),
+		Address:   dto.NullString(c.Address),
+		DateBirth: dto.NullDate(c.DateBirth),
+		UserType:  "customer",
+	}
+	if c.AddressLine1.Valid {
+		addr := dto.ShippingAddress{
+			Line1:      c.AddressLine1.String,
+			City:       dto.NullString(c.AddressCity),
+			PostalCode: dto.NullString(c.AddressPostalCode),
+			Country:    dto.NullString(c.AddressCountry),
+		}
+		resp.ShippingAddress = &addr
+		if resp.Address == "" && addr.Country != "" {
+			resp.Address = addr.String()
+		}
+	}
+	return resp
+}
+
+func toSellerProfileResponse(s *entity.SellerProfile) *dto.SellerProfileResponse {
+	return &dto.SellerProfileResponse{
+		ID:          s.ID,
+		Username:    s.Username,
+		Email:       s.Email,
+		CompanyName: dto.NullString(s.CompanyName),
+		Rating:      dto.NullFloat64(s.Rating),
+		UserType:    "seller",
+	}
+}
+
+// checkPasswordReuse rejects a new password that matches the current one or
+// any of the previous Auth.PasswordHistory-1 ones. A non-positive history
+// size disables the check.
+func (uc *authUsecase) checkPasswordReuse(ctx context.Context, u *entity.User, password string) error {
+	if uc.cfg.PasswordHistory <= 0 {
+		return nil
+	}
+
+	hashes := []string{u.PasswordHash}
+	if uc.cfg.PasswordHistory > 1 {
+		previous, err := uc.historyRepo.ListRecent(ctx, u.ID, uc.cfg.PasswordHistory-1)
+		if err != nil {
+			return appErrors.NewAppError("REPO", "failed to load password history", err)
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	for _, hash := range hashes {
+		if uc.hashManager.CompareHashPassword(hash, password) == nil {
+			return appErrors.NewAppError("BUSINESS_ERR", fmt.Sprintf("password must differ from the last %d passwords", uc.cfg.PasswordHistory), nil)
+		}
+	}
+	return nil
+}
+
+// rememberPassword moves the replaced hash into the history and prunes
+// entries the reuse check no longer looks at. Failures are logged only.
+func (uc *authUsecase) rememberPassword(ctx context.Context, userID, oldHash string) {
+	keep := uc.cfg.PasswordHistory - 1
+	if keep <= 0 {
+		return
+	}
+
+	if err := uc.historyRepo.Add(ctx, userID, oldHash); err != nil {
+		uc.logger.WithError(err).WithField("user_id", userID).Warn("failed to store password history")
+		return
+	}
+	if _, err := uc.historyRepo.Prune(ctx, userID, keep); err != nil {
+		uc.logger.WithError(err).WithField("user_id", userID).Warn("failed to prune password history")
+	}
+}
+
+// recordAudit stores an audit event for userID. Failures are logged only;
+// auditing must never fail the action itself.
+func (uc *authUsecase) recordAudit(ctx context.Context, userID, event string) {
+	client := clientinfo.FromContext(ctx)
+	err := uc.auditRepo.Record(ctx, &entity.AuditEvent{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Event:     event,
+		IPAddress: sql.NullString{String: client.IP, Valid: client.IP != ""},
+		UserAgent: sql.NullString{String: client.UserAgent, Valid: client.UserAgent != ""},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		uc.logger.WithError(err).WithFields(logrus.Fields{"user_id": userID, "event": event}).Warn("failed to record audit event")
+	}
+}
+
+func (uc *authUsecase) revokeRefreshToken(ctx context.Context, userID string) error {
+	_, err := uc.tokenRepo.RevokeAllForUser(ctx, userID)
+	return err
 }
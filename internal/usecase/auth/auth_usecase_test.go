@@ -0,0 +1,508 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"marketplace/internal/adapter/bcrypt"
+	"marketplace/internal/adapter/jwt"
+	"marketplace/internal/adapter/postgres/audit"
+	"marketplace/internal/adapter/postgres/customer"
+	"marketplace/internal/adapter/postgres/passwordhistory"
+	"marketplace/internal/adapter/postgres/pgtest"
+	"marketplace/internal/adapter/postgres/seller"
+	"marketplace/internal/adapter/postgres/token"
+	"marketplace/internal/adapter/postgres/user"
+	"marketplace/internal/adapter/postgres/verification"
+	"marketplace/internal/entity"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	appErrors "marketplace/pkg/errors"
+	adapter "marketplace/pkg/pgxpool"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func userNotFound() error {
+	return appErrors.NewAppError("NOT_FOUND", "user not found", appErrors.ErrNotFound)
+}
+
+// fakeUsers keeps users in memory. The customer and seller fakes read the
+// same map, as their profiles share the users row. Methods the tests do not
+// reach panic through the nil embedded interface.
+type fakeUsers struct {
+	user.UserRepository
+	users map[string]*entity.User
+}
+
+func (f *fakeUsers) Create(_ context.Context, u *entity.User) error {
+	stored := *u
+	f.users[u.ID] = &stored
+	return nil
+}
+
+func (f *fakeUsers) GetByID(_ context.Context, id string) (*entity.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, userNotFound()
+	}
+	found := *u
+	return &found, nil
+}
+
+func (f *fakeUsers) GetByEmail(_ context.Context, email string) (*entity.User, error) {
+	return f.find(func(u *entity.User) bool { return u.Email == email })
+}
+
+func (f *fakeUsers) GetByUsername(_ context.Context, username string) (*entity.User, error) {
+	return f.find(func(u *entity.User) bool { return u.Username == username })
+}
+
+func (f *fakeUsers) Exists(_ context.Context, field, value string) (bool, error) {
+	_, err := f.find(func(u *entity.User) bool {
+		return (field == "email" && u.Email == value) || (field == "username" && u.Username == value)
+	})
+	return err == nil, nil
+}
+
+func (f *fakeUsers) MarkVerified(_ context.Context, id string) error {
+	u, ok := f.users[id]
+	if !ok {
+		return userNotFound()
+	}
+	u.IsVerified = true
+	return nil
+}
+
+func (f *fakeUsers) find(match func(*entity.User) bool) (*entity.User, error) {
+	for _, u := range f.users {
+		if match(u) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, userNotFound()
+}
+
+type fakeCustomers struct {
+	customer.CustomerRepository
+	users *fakeUsers
+	// phones maps customer ids to their phone.
+	phones map[string]string
+	// updated is the last profile written.
+	updated *entity.CustomerProfile
+}
+
+func (f *fakeCustomers) UpdateProfile(_ context.Context, profile *entity.CustomerProfile) error {
+	f.updated = profile
+	return nil
+}
+
+func (f *fakeCustomers) PhoneTaken(_ context.Context, phone, userID string) (bool, error) {
+	for id, p := range f.phones {
+		if p == phone && id != userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeCustomers) GetByEmail(ctx context.Context, email string) (*entity.CustomerProfile, error) {
+	u, err := f.users.GetByEmail(ctx, email)
+	if err != nil || u.UserType != "customer" {
+		return nil, userNotFound()
+	}
+	return &entity.CustomerProfile{User: *u}, nil
+}
+
+func (f *fakeCustomers) GetByUsername(ctx context.Context, username string) (*entity.CustomerProfile, error) {
+	u, err := f.users.GetByUsername(ctx, username)
+	if err != nil || u.UserType != "customer" {
+		return nil, userNotFound()
+	}
+	return &entity.CustomerProfile{User: *u}, nil
+}
+
+type fakeSellers struct {
+	seller.SellerRepository
+	users *fakeUsers
+}
+
+func (f *fakeSellers) GetByEmail(ctx context.Context, email string) (*entity.SellerProfile, error) {
+	u, err := f.users.GetByEmail(ctx, email)
+	if err != nil || u.UserType != "seller" {
+		return nil, userNotFound()
+	}
+	return &entity.SellerProfile{User: *u}, nil
+}
+
+func (f *fakeSellers) GetByUsername(ctx context.Context, username string) (*entity.SellerProfile, error) {
+	u, err := f.users.GetByUsername(ctx, username)
+	if err != nil || u.UserType != "seller" {
+		return nil, userNotFound()
+	}
+	return &entity.SellerProfile{User: *u}, nil
+}
+
+// fakeVerifications maps token hashes to user ids. Consume removes the
+// token, like the repository marking it used.
+type fakeVerifications struct {
+	verification.VerificationRepository
+	tokens map[string]string
+}
+
+func (f *fakeVerifications) Create(_ context.Context, v *entity.EmailVerification) error {
+	f.tokens[v.TokenHash] = v.UserID
+	return nil
+}
+
+func (f *fakeVerifications) Consume(_ context.Context, tokenHash string, _ time.Time) (string, error) {
+	userID, ok := f.tokens[tokenHash]
+	if !ok {
+		return "", appErrors.NewAppError("INVALID_INPUT", "invalid or expired verification token", nil)
+	}
+	delete(f.tokens, tokenHash)
+	return userID, nil
+}
+
+type fakeAudit struct {
+	audit.AuditRepository
+}
+
+func (fakeAudit) Record(context.Context, *entity.AuditEvent) error {
+	return nil
+}
+
+type fakeMailer struct{}
+
+func (fakeMailer) SendVerification(context.Context, string, string) error {
+	return nil
+}
+
+// fakeJWT issues tokens that name their user.
+type fakeJWT struct {
+	jwt.JWTManager
+}
+
+func (fakeJWT) GenerateAccessToken(u *entity.User) (string, error) {
+	return "access-" + u.ID, nil
+}
+
+func (fakeJWT) GenerateRefreshToken(_ context.Context, u *entity.User) (string, error) {
+	return "refresh-" + u.ID, nil
+}
+
+func (fakeJWT) AccessTokenTTL() time.Duration {
+	return time.Minute
+}
+
+// fakeTx rolls back by restoring the users it saw before fn ran.
+type fakeTx struct {
+	users *fakeUsers
+}
+
+func (f fakeTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[string]*entity.User, len(f.users.users))
+	for id, u := range f.users.users {
+		snapshot[id] = u
+	}
+
+	if err := fn(ctx); err != nil {
+		f.users.users = snapshot
+		return err
+	}
+	return nil
+}
+
+type testEnv struct {
+	uc            *authUsecase
+	users         *fakeUsers
+	customers     *fakeCustomers
+	verifications *fakeVerifications
+}
+
+// newTestEnv builds a usecase over empty fakes. Passwords go through
+// bcrypt.FakeHasher.
+func newTestEnv(cfg config.AuthConfig) *testEnv {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	users := &fakeUsers{users: map[string]*entity.User{}}
+	customers := &fakeCustomers{users: users, phones: map[string]string{}}
+	verifications := &fakeVerifications{tokens: map[string]string{}}
+	uc := NewAuthUsecase(
+		users,
+		customers,
+		&fakeSellers{users: users},
+		nil,
+		fakeAudit{},
+		nil,
+		verifications,
+		fakeMailer{},
+		fakeTx{users: users},
+		fakeJWT{},
+		bcrypt.FakeHasher{},
+		logger,
+		cfg,
+		config.DeleteHard,
+	)
+	return &testEnv{uc: uc, users: users, customers: customers, verifications: verifications}
+}
+
+// addUser stores a user whose password is "password1".
+func (e *testEnv) addUser(t *testing.T, id, userType, username string) {
+	t.Helper()
+	hash, err := bcrypt.FakeHasher{}.GenerateHashPassword("password1")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	e.users.users[id] = &entity.User{
+		ID:           id,
+		UserType:     userType,
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: hash,
+	}
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
+
+func TestLoginInfersUserType(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{InferUserType: true})
+	env.addUser(t, "seller-1", "seller", "shopkeeper")
+
+	resp, err := env.uc.Login(context.Background(), dto.LoginRequest{Username: "shopkeeper", Password: "password1"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.AccessToken != "access-seller-1" {
+		t.Errorf("access token = %q, want one for seller-1", resp.AccessToken)
+	}
+}
+
+func TestLoginInferenceDisabledRequiresUserType(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	env.addUser(t, "seller-1", "seller", "shopkeeper")
+
+	_, err := env.uc.Login(context.Background(), dto.LoginRequest{Username: "shopkeeper", Password: "password1"})
+	assertCode(t, err, "VALIDATION")
+}
+
+func TestLoginExplicitUserTypeOverridesInference(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{InferUserType: true})
+	env.addUser(t, "seller-1", "seller", "shopkeeper")
+
+	_, err := env.uc.Login(context.Background(), dto.LoginRequest{Username: "shopkeeper", Password: "password1", UserType: "customer"})
+	assertCode(t, err, "INVALID_CREDENTIALS")
+
+	resp, err := env.uc.Login(context.Background(), dto.LoginRequest{Email: "shopkeeper@example.com", Password: "password1", UserType: "seller"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.AccessToken != "access-seller-1" {
+		t.Errorf("access token = %q, want one for seller-1", resp.AccessToken)
+	}
+}
+
+func TestAdminLoginWithoutInference(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	env.addUser(t, "admin-1", "admin", "operator")
+
+	resp, err := env.uc.Login(context.Background(), dto.LoginRequest{Username: "operator", Password: "password1", UserType: "admin"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.AccessToken != "access-admin-1" {
+		t.Errorf("access token = %q, want one for admin-1", resp.AccessToken)
+	}
+}
+
+func TestRegisterRejectsTakenEmailAndUsername(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	env.addUser(t, "seller-1", "seller", "shopkeeper")
+
+	for name, req := range map[string]dto.RegisterRequest{
+		"email":    {Username: "newcomer", Email: "shopkeeper@example.com", Password: "password1", UserType: "customer"},
+		"username": {Username: "shopkeeper", Email: "newcomer@example.com", Password: "password1", UserType: "customer"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := env.uc.Register(context.Background(), req)
+			assertCode(t, err, "DUPLICATE")
+		})
+	}
+	if len(env.users.users) != 1 {
+		t.Errorf("stored %d users, want 1", len(env.users.users))
+	}
+}
+
+// The fakes have no UpdateAuth or UpdateProfile, so reaching a write would
+// panic.
+func TestEmptyUpdatesAreRejected(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	env.addUser(t, "customer-1", "customer", "shopper")
+	ctx := context.Background()
+
+	err := env.uc.UpdateAuth(ctx, "refresh-customer-1", "customer-1", dto.UpdateAuthRequest{RefreshToken: "refresh-customer-1"})
+	assertCode(t, err, "INVALID_INPUT")
+
+	err = env.uc.UpdateProfile(ctx, "customer-1", "customer", dto.CustomerProfileRequest{})
+	assertCode(t, err, "INVALID_INPUT")
+
+	err = env.uc.UpdateProfile(ctx, "seller-1", "seller", dto.SellerProfileRequest{})
+	assertCode(t, err, "INVALID_INPUT")
+}
+
+// failingTokens stores nothing; every refresh token upsert fails.
+type failingTokens struct {
+	token.TokenRepository
+}
+
+func (failingTokens) UpsertRefreshToken(context.Context, *entity.RefreshToken) error {
+	return errors.New("connection reset")
+}
+
+// TestRegisterRollsBackWhenTokenStoreFails registers against the database
+// with a token store that fails after the user and its verification have
+// been written, and checks the transaction leaves neither behind.
+func TestRegisterRollsBackWhenTokenStoreFails(t *testing.T) {
+	pool := pgtest.New(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var cfg config.Config
+	cfg.JWT.SecretKey = "secret"
+	cfg.JWT.ExpiresIn = time.Minute
+	cfg.JWT.RefreshExpiresIn = time.Hour
+
+	tokens := failingTokens{token.NewTokenRepository(pool, logger)}
+	uc := NewAuthUsecase(
+		user.NewUserRepository(pool, logger),
+		customer.NewCustomerRepository(pool, logger),
+		seller.NewSellerRepository(pool, logger),
+		tokens,
+		audit.NewAuditRepository(pool, logger),
+		passwordhistory.NewPasswordHistoryRepository(pool, logger),
+		verification.NewVerificationRepository(pool, logger),
+		fakeMailer{},
+		adapter.NewTxManager(pool),
+		jwt.NewJWTManager(tokens, logger, cfg),
+		bcrypt.FakeHasher{},
+		logger,
+		config.AuthConfig{},
+		config.DeleteHard,
+	)
+
+	_, err := uc.Register(context.Background(), dto.RegisterRequest{
+		Username: "newcomer",
+		Email:    "newcomer@example.com",
+		Password: "password1",
+		UserType: "customer",
+	})
+	assertCode(t, err, "JWT_GENERATION")
+
+	for _, table := range []string{"users", "customers", "email_verifications"} {
+		var n int
+		if err := pool.QueryRow(context.Background(), "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if n != 0 {
+			t.Errorf("%s has %d rows after the rollback, want none", table, n)
+		}
+	}
+}
+
+func TestRegisterThenLoginChecksPassword(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	ctx := context.Background()
+
+	_, err := env.uc.Register(ctx, dto.RegisterRequest{
+		Username: "newcomer",
+		Email:    "newcomer@example.com",
+		Password: "password1",
+		UserType: "customer",
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	for _, u := range env.users.users {
+		if u.PasswordHash == "password1" {
+			t.Errorf("password stored in plain text")
+		}
+	}
+
+	_, err = env.uc.Login(ctx, dto.LoginRequest{Username: "newcomer", Password: "password2", UserType: "customer"})
+	assertCode(t, err, "INVALID_CREDENTIALS")
+
+	if _, err := env.uc.Login(ctx, dto.LoginRequest{Username: "newcomer", Password: "password1", UserType: "customer"}); err != nil {
+		t.Errorf("Login: %v", err)
+	}
+}
+
+func TestUpdateProfileKeysPhoneWhenUnique(t *testing.T) {
+	for _, unique := range []bool{true, false} {
+		env := newTestEnv(config.AuthConfig{UniquePhone: unique})
+
+		err := env.uc.UpdateProfile(context.Background(), "customer-1", "customer", dto.CustomerProfileRequest{Phone: "+15550100"})
+		if err != nil {
+			t.Fatalf("UpdateProfile (unique %v): %v", unique, err)
+		}
+		if key := env.customers.updated.PhoneKey; key.Valid != unique {
+			t.Errorf("unique %v: phone key = %+v", unique, key)
+		}
+	}
+}
+
+func TestUpdateProfileRejectsTakenPhone(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{UniquePhone: true})
+	env.customers.phones["customer-1"] = "+15550100"
+
+	err := env.uc.UpdateProfile(context.Background(), "customer-2", "customer", dto.CustomerProfileRequest{Phone: "+15550100"})
+	assertCode(t, err, "DUPLICATE")
+}
+
+func TestVerifyEmailMarksUserVerified(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{})
+	env.addUser(t, "customer-1", "customer", "shopper")
+	env.verifications.tokens[hashVerificationToken("token-1")] = "customer-1"
+	ctx := context.Background()
+
+	if err := env.uc.VerifyEmail(ctx, dto.VerifyEmailRequest{Token: "token-1"}); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !env.users.users["customer-1"].IsVerified {
+		t.Error("user is not verified")
+	}
+
+	err := env.uc.VerifyEmail(ctx, dto.VerifyEmailRequest{Token: "token-1"})
+	assertCode(t, err, "INVALID_INPUT")
+	err = env.uc.VerifyEmail(ctx, dto.VerifyEmailRequest{})
+	assertCode(t, err, "VALIDATION")
+}
+
+func TestLoginBlocksUnverifiedUsers(t *testing.T) {
+	env := newTestEnv(config.AuthConfig{BlockUnverifiedLogin: true})
+	env.addUser(t, "customer-1", "customer", "shopper")
+	env.addUser(t, "admin-1", "admin", "operator")
+	ctx := context.Background()
+
+	_, err := env.uc.Login(ctx, dto.LoginRequest{Username: "shopper", Password: "password1", UserType: "customer"})
+	assertCode(t, err, "FORBIDDEN")
+
+	// Admins have no verification flow and are never blocked.
+	if _, err := env.uc.Login(ctx, dto.LoginRequest{Username: "operator", Password: "password1", UserType: "admin"}); err != nil {
+		t.Fatalf("admin Login: %v", err)
+	}
+
+	env.users.users["customer-1"].IsVerified = true
+	if _, err := env.uc.Login(ctx, dto.LoginRequest{Username: "shopper", Password: "password1", UserType: "customer"}); err != nil {
+		t.Fatalf("Login after verification: %v", err)
+	}
+}
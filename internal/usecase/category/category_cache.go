@@ -0,0 +1,45 @@
+package category
+
+import (
+	"sync"
+	"time"
+
+	"marketplace/pkg/dto"
+)
+
+// treeCacheTTL bounds how stale the cached tree can get when categories are
+// changed by another instance.
+const treeCacheTTL = 5 * time.Minute
+
+// treeCache holds the assembled category tree. Writes through this usecase
+// invalidate it immediately.
+type treeCache struct {
+	mu        sync.RWMutex
+	nodes     []dto.CategoryNode
+	expiresAt time.Time
+}
+
+func (c *treeCache) get() ([]dto.CategoryNode, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.nodes == nil || time.Now().After(c.expiresAt) {
+		return nil, false
+	}
+	return c.nodes, true
+}
+
+func (c *treeCache) set(nodes []dto.CategoryNode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nodes = nodes
+	c.expiresAt = time.Now().Add(treeCacheTTL)
+}
+
+func (c *treeCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nodes = nil
+}
@@ -10,6 +10,10 @@ type CategoryUsecase interface {
 	Create(ctx context.Context, req *dto.CategoryDTO) (*dto.CategoryDTO, error)
 	GetByID(ctx context.Context, id string) (*entity.Category, error)
 	Update(ctx context.Context, req *dto.CategoryDTO) (*dto.CategoryDTO, error)
+	Patch(ctx context.Context, id string, req *dto.PatchCategoryRequest) (*dto.CategoryDTO, error)
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, limit, offset string) ([]*dto.CategoryDTO, error)
+	List(ctx context.Context, limit, offset int) ([]dto.CategoryDTO, error)
+	// Children lists the direct subcategories of id.
+	Children(ctx context.Context, id string) ([]dto.CategoryDTO, error)
+	Tree(ctx context.Context) ([]dto.CategoryNode, error)
 }
@@ -5,6 +5,7 @@ import (
 	errorsLib "errors"
 	"marketplace/internal/adapter/postgres/category"
 	"marketplace/internal/entity"
+	"marketplace/pkg/config"
 	"marketplace/pkg/dto"
 	"marketplace/pkg/errors"
 	"time"
@@ -14,21 +15,31 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// maxDepth caps how many ancestors checkParent walks.
+const maxDepth = 32
+
+var _ CategoryUsecase = (*categoryUsecase)(nil)
+
 type categoryUsecase struct {
 	adapter  category.CategoryRepository
 	logger   *logrus.Logger
 	validate *validator.Validate
+	tree     treeCache
+	// deletePolicy is config.DeleteSoft or config.DeleteHard.
+	deletePolicy string
 }
 
 func NewCategoryUsecase(
 	adapter category.CategoryRepository,
 	logger *logrus.Logger,
 	validate *validator.Validate,
+	deletePolicy string,
 ) *categoryUsecase {
 	return &categoryUsecase{
-		adapter:  adapter,
-		logger:   logger,
-		validate: validate,
+		adapter:      adapter,
+		logger:       logger,
+		validate:     validate,
+		deletePolicy: deletePolicy,
 	}
 }
 
@@ -41,7 +52,8 @@ func (uc *categoryUsecase) Create(ctx context.Context, req *dto.CategoryDTO) (*d
 		return nil, errors.NewAppError("INVALID_INPUT", "empty request", nil)
 	}
 
-	if err := uc.validate.StructCtx(ctx, &req); err != nil {
+	// The id is generated here, so the request need not carry one.
+	if err := uc.validate.StructExceptCtx(ctx, req, "CategoryID"); err != nil {
 		var validatorErrs validator.ValidationErrors
 		if errorsLib.As(err, &validatorErrs) {
 			var msgs []string
@@ -63,10 +75,15 @@ func (uc *categoryUsecase) Create(ctx context.Context, req *dto.CategoryDTO) (*d
 	category := &entity.Category{
 		ID:        uuid.NewString(),
 		Name:      req.Name,
+		ParentID:  req.ParentID,
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
 
+	if err := uc.checkParent(ctx, category.ID, category.ParentID); err != nil {
+		return nil, err
+	}
+
 	if err := uc.adapter.Create(ctx, category); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
@@ -75,11 +92,9 @@ func (uc *categoryUsecase) Create(ctx context.Context, req *dto.CategoryDTO) (*d
 		}).Warn("Failed create category")
 		return nil, errors.NewAppError("CREATE_ERR", "failed create category", err)
 	}
+	uc.tree.invalidate()
 
-	resp := &dto.CategoryDTO{
-		CategoryID: category.ID,
-		Name:       category.Name,
-	}
+	resp := toCategoryDTO(*category)
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation":     "create",
@@ -101,6 +116,9 @@ func (uc *categoryUsecase) GetByID(ctx context.Context, id string) (*entity.Cate
 
 	category, err := uc.adapter.GetByID(ctx, id)
 	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "category not found", err)
+		}
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "get_by_id",
 			"id":        id,
@@ -127,7 +145,7 @@ func (uc *categoryUsecase) Update(ctx context.Context, req *dto.CategoryDTO) (*d
 		return nil, errors.NewAppError("INPUT_ERR", "empty input", nil)
 	}
 
-	if err := uc.validate.StructCtx(ctx, &req); err != nil {
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
 		var validatorErrs validator.ValidationErrors
 		if errorsLib.As(err, &validatorErrs) {
 			var msgs []string
@@ -146,12 +164,30 @@ func (uc *categoryUsecase) Update(ctx context.Context, req *dto.CategoryDTO) (*d
 		return nil, errors.NewAppError("VALIDATE_ERR", "unexpected validation error", err)
 	}
 
+	exists, err := uc.adapter.Exists(ctx, "id", req.CategoryID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"id":        req.CategoryID,
+			"error":     err,
+		}).Warn("Failed check category exists")
+		return nil, errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+	if !exists {
+		return nil, errors.NewAppError("NOT_FOUND", "category not found", nil)
+	}
+
 	category := &entity.Category{
 		ID:        req.CategoryID,
 		Name:      req.Name,
+		ParentID:  req.ParentID,
 		UpdatedAt: time.Now().UTC(),
 	}
 
+	if err := uc.checkParent(ctx, category.ID, category.ParentID); err != nil {
+		return nil, err
+	}
+
 	if err := uc.adapter.Update(ctx, category); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "update",
@@ -161,6 +197,7 @@ func (uc *categoryUsecase) Update(ctx context.Context, req *dto.CategoryDTO) (*d
 		}).Warn("Failed update category")
 		return nil, errors.NewAppError("UPDATE_ERR", "failed update category", err)
 	}
+	uc.tree.invalidate()
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation": "update",
@@ -171,6 +208,71 @@ func (uc *categoryUsecase) Update(ctx context.Context, req *dto.CategoryDTO) (*d
 	return req, nil
 }
 
+func (uc *categoryUsecase) Patch(ctx context.Context, id string, req *dto.PatchCategoryRequest) (*dto.CategoryDTO, error) {
+	if id == "" || req == nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "patch",
+			"id":        id,
+		}).Warn("Empty input")
+		return nil, errors.NewAppError("INPUT_ERR", "empty input", nil)
+	}
+
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "patch",
+			"error":     err,
+		}).Warn("Failed validation")
+		return nil, errors.NewAppError("VALIDATE_ERR", "failed validate patch request", err)
+	}
+
+	current, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "category not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "patch",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get by ID")
+		return nil, errors.NewAppError("GET_ERR", "failed get by id", err)
+	}
+
+	resp := toCategoryDTO(*current)
+
+	// Nothing to change: skip the write so updated_at stays as it is.
+	if req.Name == nil || *req.Name == current.Name {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "patch",
+			"id":        id,
+		}).Info("Category unchanged")
+		return resp, nil
+	}
+
+	current.Name = *req.Name
+	current.UpdatedAt = time.Now().UTC()
+
+	if err := uc.adapter.Update(ctx, current); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "patch",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed update category")
+		return nil, errors.NewAppError("UPDATE_ERR", "failed update category", err)
+	}
+	uc.tree.invalidate()
+
+	resp.Name = current.Name
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "patch",
+		"id":        id,
+		"name":      current.Name,
+	}).Info("Category updated successfully")
+
+	return resp, nil
+}
+
 func (uc *categoryUsecase) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		uc.logger.WithFields(logrus.Fields{
@@ -180,18 +282,38 @@ func (uc *categoryUsecase) Delete(ctx context.Context, id string) error {
 		return errors.NewAppError("INPUT_ERR", "empty id", nil)
 	}
 
-	if err := uc.adapter.Delete(ctx, id); err != nil {
+	exists, err := uc.adapter.Exists(ctx, "id", id)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "delete",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed check category exists")
+		return errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+	if !exists {
+		return errors.NewAppError("NOT_FOUND", "category not found", nil)
+	}
+
+	deleteFn := uc.adapter.Delete
+	if uc.deletePolicy == config.DeleteSoft {
+		deleteFn = uc.adapter.SoftDelete
+	}
+	if err := deleteFn(ctx, id); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "delete",
 			"id":        id,
+			"policy":    uc.deletePolicy,
 			"error":     err,
 		}).Warn("Failed delete category")
 		return errors.NewAppError("DELETE_ERR", "failed delete category", err)
 	}
+	uc.tree.invalidate()
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation": "delete",
 		"id":        id,
+		"policy":    uc.deletePolicy,
 	}).Info("Category successfully deleted")
 
 	return nil
@@ -225,13 +347,9 @@ func (uc *categoryUsecase) List(ctx context.Context, limit, offset int) ([]dto.C
 		return nil, errors.NewAppError("LIST_ERR", "failed list categories", err)
 	}
 
-	var list []dto.CategoryDTO
+	list := make([]dto.CategoryDTO, 0, len(categories))
 	for _, category := range categories {
-		dtoCategory := dto.CategoryDTO{
-			CategoryID: category.ID,
-			Name:       category.Name,
-		}
-		list = append(list, dtoCategory)
+		list = append(list, *toCategoryDTO(category))
 	}
 
 	uc.logger.WithFields(logrus.Fields{
@@ -241,3 +359,163 @@ func (uc *categoryUsecase) List(ctx context.Context, limit, offset int) ([]dto.C
 
 	return list, nil
 }
+
+func (uc *categoryUsecase) Children(ctx context.Context, id string) ([]dto.CategoryDTO, error) {
+	if id == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "empty id", nil)
+	}
+
+	exists, err := uc.adapter.Exists(ctx, "id", id)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "children",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed check category exists")
+		return nil, errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+	if !exists {
+		return nil, errors.NewAppError("NOT_FOUND", "category not found", errors.ErrNotFound)
+	}
+
+	children, err := uc.adapter.ListChildren(ctx, id)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "children",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed list children")
+		return nil, errors.NewAppError("LIST_ERR", "failed list subcategories", err)
+	}
+
+	list := make([]dto.CategoryDTO, 0, len(children))
+	for _, child := range children {
+		list = append(list, *toCategoryDTO(child))
+	}
+
+	return list, nil
+}
+
+// checkParent rejects a parent that does not exist or that would put the
+// category id among its own ancestors. The walk is bounded by maxDepth so a
+// cycle already in the table cannot loop forever.
+func (uc *categoryUsecase) checkParent(ctx context.Context, id string, parentID *string) error {
+	if parentID == nil {
+		return nil
+	}
+
+	next := *parentID
+	for depth := 0; depth < maxDepth; depth++ {
+		if next == id {
+			return errors.NewAppError("BUSINESS_ERR", "category cannot be its own ancestor", nil)
+		}
+
+		ancestor, err := uc.adapter.GetByID(ctx, next)
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			if next == *parentID {
+				return errors.NewAppError("INVALID_INPUT", "parent category not found", nil)
+			}
+			return nil
+		}
+		if err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "check_parent",
+				"id":        id,
+				"parent_id": *parentID,
+				"error":     err,
+			}).Warn("Failed get ancestor")
+			return errors.NewAppError("GET_ERR", "failed get parent category", err)
+		}
+		if ancestor.ParentID == nil {
+			return nil
+		}
+		next = *ancestor.ParentID
+	}
+
+	return errors.NewAppError("BUSINESS_ERR", "category tree is too deep", nil)
+}
+
+func toCategoryDTO(c entity.Category) *dto.CategoryDTO {
+	return &dto.CategoryDTO{
+		CategoryID: c.ID,
+		Name:       c.Name,
+		ParentID:   c.ParentID,
+	}
+}
+
+func (uc *categoryUsecase) Tree(ctx context.Context) ([]dto.CategoryNode, error) {
+	if nodes, ok := uc.tree.get(); ok {
+		return nodes, nil
+	}
+
+	categories, err := uc.adapter.ListAll(ctx)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "tree",
+			"error":     err,
+		}).Warn("Failed list categories")
+		return nil, errors.NewAppError("LIST_ERR", "failed list categories", err)
+	}
+
+	nodes, skipped := buildTree(categories)
+	if skipped > 0 {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "tree",
+			"skipped":   skipped,
+		}).Warn("Categories unreachable from a root, possible parent cycle")
+	}
+
+	uc.tree.set(nodes)
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation":  "tree",
+		"root_count": len(nodes),
+	}).Info("Category tree built")
+
+	return nodes, nil
+}
+
+// buildTree nests categories under their parents. Categories without a known
+// parent become roots. Categories that are only reachable through a cycle are
+// left out and counted in skipped.
+func buildTree(categories []entity.Category) (roots []dto.CategoryNode, skipped int) {
+	known := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+
+	children := make(map[string][]entity.Category)
+	var rootCategories []entity.Category
+	for _, c := range categories {
+		if c.ParentID == nil || !known[*c.ParentID] {
+			rootCategories = append(rootCategories, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	visited := make(map[string]bool, len(categories))
+	var build func(c entity.Category) dto.CategoryNode
+	build = func(c entity.Category) dto.CategoryNode {
+		visited[c.ID] = true
+		node := dto.CategoryNode{
+			ID:       c.ID,
+			Name:     c.Name,
+			Children: []dto.CategoryNode{},
+		}
+		for _, child := range children[c.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	roots = []dto.CategoryNode{}
+	for _, c := range rootCategories {
+		roots = append(roots, build(c))
+	}
+
+	return roots, len(categories) - len(visited)
+}
@@ -0,0 +1,154 @@
+package category
+
+import (
+	"context"
+	"encoding/json"
+	errorsLib "errors"
+	"io"
+	"marketplace/internal/adapter/postgres/category"
+	"marketplace/internal/entity"
+	"marketplace/pkg/dto"
+	"marketplace/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeCategories serves GetByID from a map and counts Update calls. Other
+// methods panic through the nil embedded interface.
+type fakeCategories struct {
+	category.CategoryRepository
+	categories map[string]*entity.Category
+	updates    int
+}
+
+func (f *fakeCategories) GetByID(_ context.Context, id string) (*entity.Category, error) {
+	c, ok := f.categories[id]
+	if !ok {
+		return nil, errors.NewAppError("NOT_FOUND", "category not found", errors.ErrNotFound)
+	}
+	found := *c
+	return &found, nil
+}
+
+func (f *fakeCategories) Exists(_ context.Context, field, value string) (bool, error) {
+	_, ok := f.categories[value]
+	return field == "id" && ok, nil
+}
+
+// List returns nil, as a scan loop over no rows would.
+func (f *fakeCategories) List(context.Context, int, int) ([]entity.Category, error) {
+	return nil, nil
+}
+
+func (f *fakeCategories) Update(_ context.Context, c *entity.Category) error {
+	f.updates++
+	stored := *c
+	f.categories[c.ID] = &stored
+	return nil
+}
+
+func newTestUsecase(categories ...*entity.Category) (*categoryUsecase, *fakeCategories) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo := &fakeCategories{categories: map[string]*entity.Category{}}
+	for _, c := range categories {
+		repo.categories[c.ID] = c
+	}
+	return NewCategoryUsecase(repo, logger, validator.New(), ""), repo
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !errorsLib.As(err, &appErr) || appErr.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
+
+func TestPatchWithoutChangesSkipsUpdate(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	uc, repo := newTestUsecase(&entity.Category{ID: "c1", Name: "Tools", UpdatedAt: updatedAt})
+	same := "Tools"
+
+	for name, req := range map[string]*dto.PatchCategoryRequest{
+		"no name":   {},
+		"same name": {Name: &same},
+	} {
+		resp, err := uc.Patch(context.Background(), "c1", req)
+		if err != nil {
+			t.Fatalf("%s: Patch: %v", name, err)
+		}
+		if resp.Name != "Tools" {
+			t.Errorf("%s: name = %q, want %q", name, resp.Name, "Tools")
+		}
+	}
+
+	if repo.updates != 0 {
+		t.Errorf("Update called %d times, want none", repo.updates)
+	}
+	if got := repo.categories["c1"].UpdatedAt; !got.Equal(updatedAt) {
+		t.Errorf("updated_at = %v, want %v", got, updatedAt)
+	}
+}
+
+func TestPatchRenames(t *testing.T) {
+	uc, repo := newTestUsecase(&entity.Category{ID: "c1", Name: "Tools"})
+	name := "Garden"
+
+	if _, err := uc.Patch(context.Background(), "c1", &dto.PatchCategoryRequest{Name: &name}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if repo.updates != 1 || repo.categories["c1"].Name != "Garden" {
+		t.Errorf("updates = %d, name = %q, want one update to %q", repo.updates, repo.categories["c1"].Name, "Garden")
+	}
+}
+
+func TestListEmptyIsJSONArray(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	categories, err := uc.List(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	body, err := json.Marshal(categories)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != "[]" {
+		t.Errorf("categories = %s, want []", body)
+	}
+}
+
+func TestDeleteUnknownIsNotFound(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	assertCode(t, uc.Delete(context.Background(), "missing"), "NOT_FOUND")
+}
+
+func TestGetByIDUnknownIsNotFound(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	_, err := uc.GetByID(context.Background(), "missing")
+	assertCode(t, err, "NOT_FOUND")
+}
+
+func TestChildrenRejectsEmptyID(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	_, err := uc.Children(context.Background(), "")
+	assertCode(t, err, "INVALID_INPUT")
+}
+
+func TestChildrenOfUnknownIsNotFound(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	_, err := uc.Children(context.Background(), "missing")
+	assertCode(t, err, "NOT_FOUND")
+	if !errorsLib.Is(err, errors.ErrNotFound) {
+		t.Errorf("error = %v, want it to wrap ErrNotFound", err)
+	}
+}
@@ -7,8 +7,19 @@ import (
 )
 
 type ImageUsecase interface {
-	Create(ctx context.Context, req *dto.ImageDTO) (*dto.ImageDTO, error)
+	Create(ctx context.Context, req *dto.ImageDTO, sellerID string) (*dto.ImageDTO, error)
 	GetByID(ctx context.Context, id string) (*entity.ProductImage, error)
-	Delete(ctx context.Context, id string) error
+	// Delete removes the image if it belongs to one of the seller's products.
+	Delete(ctx context.Context, id, sellerID string) error
+	DeleteAllForProduct(ctx context.Context, productID, sellerID string) (int64, error)
+	// BulkDelete deletes the seller's images among ids and skips the rest.
+	BulkDelete(ctx context.Context, req dto.BulkDeleteImagesRequest, sellerID string) (*dto.BulkDeleteImagesResponse, error)
 	ListByProductID(ctx context.Context, productID string, limit, offset int) ([]dto.ImageDTO, error)
+	// ListByProductIDs loads the images of several products in one query
+	// and returns them keyed by product id. A non-positive perProduct loads
+	// every image.
+	ListByProductIDs(ctx context.Context, productIDs []string, perProduct int) (map[string][]dto.ImageDTO, error)
+	CountByProductID(ctx context.Context, productID string) (int64, error)
+	// SetPrimary makes the image the primary image of its product.
+	SetPrimary(ctx context.Context, id, sellerID string) error
 }
@@ -3,10 +3,14 @@ package images
 import (
 	"context"
 	errorsLib "errors"
+	"marketplace/internal/adapter/postgres/product"
 	productimage "marketplace/internal/adapter/postgres/product_image"
 	"marketplace/internal/entity"
+	"marketplace/pkg/config"
 	"marketplace/pkg/dto"
 	"marketplace/pkg/errors"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -14,25 +18,33 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+var _ ImageUsecase = (*imageUsecase)(nil)
+
 type imageUsecase struct {
-	adapter  productimage.ProductImageRepository
-	logger   *logrus.Logger
-	validate *validator.Validate
+	adapter     productimage.ProductImageRepository
+	productRepo product.ProductRepository
+	logger      *logrus.Logger
+	validate    *validator.Validate
+	cfg         config.ImagesConfig
 }
 
 func NewImageUsecase(
 	adapter productimage.ProductImageRepository,
+	productRepo product.ProductRepository,
 	logger *logrus.Logger,
 	validate *validator.Validate,
+	cfg config.ImagesConfig,
 ) *imageUsecase {
 	return &imageUsecase{
-		adapter:  adapter,
-		logger:   logger,
-		validate: validate,
+		adapter:     adapter,
+		productRepo: productRepo,
+		logger:      logger,
+		validate:    validate,
+		cfg:         cfg,
 	}
 }
 
-func (uc *imageUsecase) Create(ctx context.Context, req *dto.ImageDTO) (*dto.ImageDTO, error) {
+func (uc *imageUsecase) Create(ctx context.Context, req *dto.ImageDTO, sellerID string) (*dto.ImageDTO, error) {
 	if req == nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
@@ -41,7 +53,7 @@ func (uc *imageUsecase) Create(ctx context.Context, req *dto.ImageDTO) (*dto.Ima
 		return nil, errors.NewAppError("INPUT_ERR", "empty input", nil)
 	}
 
-	if err := uc.validate.StructCtx(ctx, &req); err != nil {
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
 		var validatorErrs validator.ValidationErrors
 		if errorsLib.As(err, &validatorErrs) {
 			var msgs []string
@@ -60,6 +72,19 @@ func (uc *imageUsecase) Create(ctx context.Context, req *dto.ImageDTO) (*dto.Ima
 		return nil, errors.NewAppError("VALIDATE_ERR", "unexpected validation error", err)
 	}
 
+	if err := uc.checkURL(req.URL); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "create",
+			"url":       req.URL,
+			"error":     err,
+		}).Warn("Image URL rejected")
+		return nil, err
+	}
+
+	if err := uc.checkOwnership(ctx, req.ProductID, sellerID); err != nil {
+		return nil, err
+	}
+
 	image := &entity.ProductImage{
 		ID:        uuid.NewString(),
 		ProductID: req.ProductID,
@@ -78,11 +103,12 @@ func (uc *imageUsecase) Create(ctx context.Context, req *dto.ImageDTO) (*dto.Ima
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation":  "create",
+		"id":         image.ID,
 		"product_id": req.ProductID,
 		"url":        req.URL,
 	}).Info("Image successfully created")
 
-	return req, nil
+	return toImageDTO(*image), nil
 }
 
 func (uc *imageUsecase) GetByID(ctx context.Context, id string) (*entity.ProductImage, error) {
@@ -96,6 +122,9 @@ func (uc *imageUsecase) GetByID(ctx context.Context, id string) (*entity.Product
 
 	image, err := uc.adapter.GetByID(ctx, id)
 	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "image not found", err)
+		}
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "get_by_id",
 			"id":        id,
@@ -113,7 +142,7 @@ func (uc *imageUsecase) GetByID(ctx context.Context, id string) (*entity.Product
 	return image, nil
 }
 
-func (uc *imageUsecase) Delete(ctx context.Context, id string) error {
+func (uc *imageUsecase) Delete(ctx context.Context, id, sellerID string) error {
 	if id == "" {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "delete",
@@ -122,6 +151,23 @@ func (uc *imageUsecase) Delete(ctx context.Context, id string) error {
 		return errors.NewAppError("INPUT_ERR", "empty id", nil)
 	}
 
+	image, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "image not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "delete",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get image")
+		return errors.NewAppError("GET_ERR", "failed get image", err)
+	}
+
+	if err := uc.checkOwnership(ctx, image.ProductID, sellerID); err != nil {
+		return err
+	}
+
 	if err := uc.adapter.Delete(ctx, id); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "delete",
@@ -132,13 +178,193 @@ func (uc *imageUsecase) Delete(ctx context.Context, id string) error {
 	}
 
 	uc.logger.WithFields(logrus.Fields{
-		"operation": "delete",
-		"id":        id,
+		"operation":  "delete",
+		"id":         id,
+		"product_id": image.ProductID,
 	}).Info("Image successfully deleted")
 
 	return nil
 }
 
+func (uc *imageUsecase) SetPrimary(ctx context.Context, id, sellerID string) error {
+	if id == "" {
+		return errors.NewAppError("INPUT_ERR", "empty id", nil)
+	}
+
+	image, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "image not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "set_primary",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get image")
+		return errors.NewAppError("GET_ERR", "failed get image", err)
+	}
+
+	if err := uc.checkOwnership(ctx, image.ProductID, sellerID); err != nil {
+		return err
+	}
+
+	if err := uc.adapter.SetPrimary(ctx, id, image.ProductID); err != nil {
+		var appErr *errors.AppError
+		if errorsLib.As(err, &appErr) && appErr.Code() == "NOT_FOUND" {
+			return err
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "set_primary",
+			"id":         id,
+			"product_id": image.ProductID,
+			"error":      err,
+		}).Warn("Failed set primary image")
+		return errors.NewAppError("UPDATE_ERR", "failed set primary image", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation":  "set_primary",
+		"id":         id,
+		"product_id": image.ProductID,
+	}).Info("Primary image set")
+
+	return nil
+}
+
+func (uc *imageUsecase) DeleteAllForProduct(ctx context.Context, productID, sellerID string) (int64, error) {
+	if productID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "delete_all",
+			"product_id": productID,
+		}).Warn("Empty input")
+		return 0, errors.NewAppError("INPUT_ERR", "empty product id", nil)
+	}
+
+	if err := uc.checkOwnership(ctx, productID, sellerID); err != nil {
+		return 0, err
+	}
+
+	deleted, err := uc.adapter.DeleteByProductID(ctx, productID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "delete_all",
+			"product_id": productID,
+			"error":      err,
+		}).Warn("Failed delete images")
+		return 0, errors.NewAppError("DELETE_ERR", "failed delete images", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation":  "delete_all",
+		"product_id": productID,
+		"deleted":    deleted,
+	}).Info("Product images successfully deleted")
+
+	return deleted, nil
+}
+
+func (uc *imageUsecase) BulkDelete(ctx context.Context, req dto.BulkDeleteImagesRequest, sellerID string) (*dto.BulkDeleteImagesResponse, error) {
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "bulk_delete",
+			"error":     err,
+		}).Warn("Validation failed")
+		return nil, errors.NewAppError("VALIDATION", "ids must hold 1 to 100 image ids", err)
+	}
+
+	// Ownership is resolved per product, so images of one product share a lookup.
+	owned := make(map[string]bool)
+	seen := make(map[string]bool, len(req.IDs))
+	var deletable []string
+	skipped := make([]string, 0)
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		image, err := uc.adapter.GetByID(ctx, id)
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			skipped = append(skipped, id)
+			continue
+		}
+		if err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "bulk_delete",
+				"id":        id,
+				"error":     err,
+			}).Warn("Failed get image")
+			return nil, errors.NewAppError("GET_ERR", "failed get image", err)
+		}
+
+		isOwner, checked := owned[image.ProductID]
+		if !checked {
+			err := uc.checkOwnership(ctx, image.ProductID, sellerID)
+			var appErr *errors.AppError
+			if err != nil && !(errorsLib.As(err, &appErr) && (appErr.Code() == "NOT_FOUND" || appErr.Code() == "FORBIDDEN")) {
+				return nil, err
+			}
+			isOwner = err == nil
+			owned[image.ProductID] = isOwner
+		}
+		if !isOwner {
+			skipped = append(skipped, id)
+			continue
+		}
+
+		deletable = append(deletable, id)
+	}
+
+	var deleted int64
+	if len(deletable) > 0 {
+		var err error
+		deleted, err = uc.adapter.DeleteByIDs(ctx, deletable)
+		if err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "bulk_delete",
+				"seller_id": sellerID,
+				"error":     err,
+			}).Warn("Failed delete images")
+			return nil, errors.NewAppError("DELETE_ERR", "failed delete images", err)
+		}
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "bulk_delete",
+		"seller_id": sellerID,
+		"deleted":   deleted,
+		"skipped":   len(skipped),
+	}).Info("Images successfully deleted")
+
+	return &dto.BulkDeleteImagesResponse{
+		Deleted:    deleted,
+		Skipped:    len(skipped),
+		SkippedIDs: skipped,
+	}, nil
+}
+
+func (uc *imageUsecase) CountByProductID(ctx context.Context, productID string) (int64, error) {
+	if productID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "count",
+			"id":        productID,
+		}).Warn("Empty input")
+		return 0, errors.NewAppError("INVALID_INPUT", "empty id", nil)
+	}
+
+	count, err := uc.adapter.CountByProductID(ctx, productID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "count",
+			"product_id": productID,
+			"error":      err,
+		}).Warn("Failed count images")
+		return 0, errors.NewAppError("COUNT_ERR", "failed count images", err)
+	}
+
+	return count, nil
+}
+
 func (uc *imageUsecase) ListByProductID(ctx context.Context, productID string, limit, offset int) ([]dto.ImageDTO, error) {
 	if productID == "" {
 		uc.logger.WithFields(logrus.Fields{
@@ -148,7 +374,7 @@ func (uc *imageUsecase) ListByProductID(ctx context.Context, productID string, l
 		return nil, errors.NewAppError("INPUT_ERR", "empty id", nil)
 	}
 
-	if limit < 0 || limit > 20 {
+	if limit <= 0 || limit > 20 {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "list",
 			"limit":     limit,
@@ -174,13 +400,9 @@ func (uc *imageUsecase) ListByProductID(ctx context.Context, productID string, l
 		return nil, errors.NewAppError("LIST_ERR", "failed list images", err)
 	}
 
-	var list []dto.ImageDTO
+	list := make([]dto.ImageDTO, 0, len(images))
 	for _, image := range images {
-		dtoImage := dto.ImageDTO{
-			ProductID: image.ID,
-			URL:       image.URL,
-		}
-		list = append(list, dtoImage)
+		list = append(list, *toImageDTO(image))
 	}
 
 	uc.logger.WithFields(logrus.Fields{
@@ -191,3 +413,88 @@ func (uc *imageUsecase) ListByProductID(ctx context.Context, productID string, l
 
 	return list, nil
 }
+
+func (uc *imageUsecase) ListByProductIDs(ctx context.Context, productIDs []string, perProduct int) (map[string][]dto.ImageDTO, error) {
+	images, err := uc.adapter.ListByProductIDs(ctx, productIDs, perProduct)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":     "list",
+			"product_count": len(productIDs),
+			"error":         err,
+		}).Warn("Failed list images")
+		return nil, errors.NewAppError("LIST_ERR", "failed list images", err)
+	}
+
+	byProduct := make(map[string][]dto.ImageDTO, len(productIDs))
+	for _, image := range images {
+		byProduct[image.ProductID] = append(byProduct[image.ProductID], *toImageDTO(image))
+	}
+	return byProduct, nil
+}
+
+func toImageDTO(image entity.ProductImage) *dto.ImageDTO {
+	return &dto.ImageDTO{
+		ID:        image.ID,
+		ProductID: image.ProductID,
+		URL:       image.URL,
+		IsPrimary: image.IsPrimary,
+	}
+}
+
+func (uc *imageUsecase) checkOwnership(ctx context.Context, productID, sellerID string) error {
+	p, err := uc.productRepo.GetByID(ctx, productID)
+	if errorsLib.Is(err, errors.ErrNotFound) {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "check_ownership",
+			"product_id": productID,
+		}).Warn("Product not found")
+		return errors.NewAppError("NOT_FOUND", "product not found", err)
+	}
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "check_ownership",
+			"product_id": productID,
+			"error":      err,
+		}).Warn("Failed get product")
+		return errors.NewAppError("GET_ERR", "failed get product", err)
+	}
+	if p.SellerID != sellerID {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":  "check_ownership",
+			"product_id": productID,
+			"seller_id":  sellerID,
+		}).Warn("Product belongs to another seller")
+		return errors.NewAppError("FORBIDDEN", "product belongs to another seller", nil)
+	}
+
+	return nil
+}
+
+// checkURL requires an absolute http(s) URL whose host is in the configured
+// allowlist.
+func (uc *imageUsecase) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.NewAppError("INVALID_INPUT", "image url must be an absolute http(s) url", err)
+	}
+
+	if len(uc.cfg.AllowedHosts) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range uc.cfg.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+			continue
+		}
+		if host == allowed {
+			return nil
+		}
+	}
+
+	return errors.NewAppError("INVALID_INPUT", "image host is not allowed: "+host, nil)
+}
@@ -0,0 +1,213 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	errorsLib "errors"
+	"fmt"
+	"io"
+	"marketplace/internal/adapter/postgres/product"
+	productimage "marketplace/internal/adapter/postgres/product_image"
+	"marketplace/internal/entity"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	"marketplace/pkg/errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeProducts serves GetByID from a map. Other methods are not used by the
+// image usecase and panic through the nil embedded interface.
+type fakeProducts struct {
+	product.ProductRepository
+	products map[string]*entity.Product
+}
+
+func (f *fakeProducts) GetByID(_ context.Context, id string) (*entity.Product, error) {
+	p, ok := f.products[id]
+	if !ok {
+		return nil, errors.NewAppError("NOT_FOUND", "product not found", errors.ErrNotFound)
+	}
+	return p, nil
+}
+
+// fakeImages keeps images in memory, in insertion order.
+type fakeImages struct {
+	productimage.ProductImageRepository
+	images []entity.ProductImage
+}
+
+func (f *fakeImages) Create(_ context.Context, image *entity.ProductImage) error {
+	f.images = append(f.images, *image)
+	return nil
+}
+
+func (f *fakeImages) GetByID(_ context.Context, id string) (*entity.ProductImage, error) {
+	for i := range f.images {
+		if f.images[i].ID == id {
+			image := f.images[i]
+			return &image, nil
+		}
+	}
+	return nil, errors.NewAppError("NOT_FOUND", "image not found", errors.ErrNotFound)
+}
+
+// ListByProductIDs ignores perProduct; the database test covers the cap.
+func (f *fakeImages) ListByProductIDs(_ context.Context, productIDs []string, _ int) ([]entity.ProductImage, error) {
+	var images []entity.ProductImage
+	for _, image := range f.images {
+		for _, id := range productIDs {
+			if image.ProductID == id {
+				images = append(images, image)
+			}
+		}
+	}
+	return images, nil
+}
+
+func (f *fakeImages) ListByProductID(_ context.Context, productID string, limit, _ int) ([]entity.ProductImage, error) {
+	var images []entity.ProductImage
+	for _, image := range f.images {
+		if image.ProductID == productID {
+			images = append(images, image)
+		}
+	}
+	if limit < len(images) {
+		images = images[:limit]
+	}
+	return images, nil
+}
+
+func newTestUsecase(products ...*entity.Product) (*imageUsecase, *fakeImages) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo := &fakeProducts{products: map[string]*entity.Product{}}
+	for _, p := range products {
+		repo.products[p.ID] = p
+	}
+	images := &fakeImages{}
+	return NewImageUsecase(images, repo, logger, validator.New(), config.ImagesConfig{}), images
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !errorsLib.As(err, &appErr) || appErr.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
+
+func TestCreateRejectsUnknownProduct(t *testing.T) {
+	uc, images := newTestUsecase()
+
+	_, err := uc.Create(context.Background(), &dto.ImageDTO{ProductID: "missing", URL: "https://cdn.example.com/a.png"}, "seller-1")
+	assertCode(t, err, "NOT_FOUND")
+	if len(images.images) != 0 {
+		t.Errorf("stored %d images, want none", len(images.images))
+	}
+}
+
+func TestCreateRejectsOtherSellersProduct(t *testing.T) {
+	uc, images := newTestUsecase(&entity.Product{ID: "p1", SellerID: "seller-1"})
+
+	_, err := uc.Create(context.Background(), &dto.ImageDTO{ProductID: "p1", URL: "https://cdn.example.com/a.png"}, "seller-2")
+	assertCode(t, err, "FORBIDDEN")
+	if len(images.images) != 0 {
+		t.Errorf("stored %d images, want none", len(images.images))
+	}
+}
+
+func TestCreateStoresImageForOwner(t *testing.T) {
+	uc, images := newTestUsecase(&entity.Product{ID: "p1", SellerID: "seller-1"})
+
+	resp, err := uc.Create(context.Background(), &dto.ImageDTO{ProductID: "p1", URL: "https://cdn.example.com/a.png"}, "seller-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(images.images) != 1 || images.images[0].ID != resp.ID || images.images[0].ProductID != "p1" {
+		t.Errorf("stored %+v, want one image %s of p1", images.images, resp.ID)
+	}
+}
+
+func TestListByProductIDEmptyIsJSONArray(t *testing.T) {
+	uc, _ := newTestUsecase(&entity.Product{ID: "p1", SellerID: "seller-1"})
+
+	images, err := uc.ListByProductID(context.Background(), "p1", 10, 0)
+	if err != nil {
+		t.Fatalf("ListByProductID: %v", err)
+	}
+	body, err := json.Marshal(images)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != "[]" {
+		t.Errorf("images = %s, want []", body)
+	}
+}
+
+// TestListByProductIDClampsLimit covers the limit fallback; paging itself
+// is the repository's and is tested against the database.
+func TestListByProductIDClampsLimit(t *testing.T) {
+	uc, images := newTestUsecase(&entity.Product{ID: "p1", SellerID: "seller-1"})
+	for i := 0; i < 25; i++ {
+		images.images = append(images.images, entity.ProductImage{ID: fmt.Sprintf("img-%02d", i), ProductID: "p1"})
+	}
+
+	// Limits outside 1..20 fall back to 20.
+	for _, limit := range []int{0, 50} {
+		list, err := uc.ListByProductID(context.Background(), "p1", limit, 0)
+		if err != nil {
+			t.Fatalf("ListByProductID(%d): %v", limit, err)
+		}
+		if len(list) != 20 {
+			t.Errorf("ListByProductID(%d) returned %d images, want 20", limit, len(list))
+		}
+	}
+}
+
+func TestListByProductIDsGroupsByProduct(t *testing.T) {
+	uc, images := newTestUsecase()
+	images.images = []entity.ProductImage{
+		{ID: "img-1", ProductID: "p1"},
+		{ID: "img-2", ProductID: "p2"},
+		{ID: "img-3", ProductID: "p1"},
+	}
+
+	byProduct, err := uc.ListByProductIDs(context.Background(), []string{"p1", "p2", "p3"}, 0)
+	if err != nil {
+		t.Fatalf("ListByProductIDs: %v", err)
+	}
+	want := map[string][]string{"p1": {"img-1", "img-3"}, "p2": {"img-2"}}
+	for productID, ids := range want {
+		list := byProduct[productID]
+		if len(list) != len(ids) {
+			t.Errorf("%s has %d images, want %d", productID, len(list), len(ids))
+			continue
+		}
+		for i, id := range ids {
+			if list[i].ID != id {
+				t.Errorf("%s image %d = %s, want %s", productID, i, list[i].ID, id)
+			}
+		}
+	}
+	if _, ok := byProduct["p3"]; ok {
+		t.Error("p3 has images, want none")
+	}
+}
+
+func TestGetByIDUnknownIsNotFound(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	_, err := uc.GetByID(context.Background(), "missing")
+	assertCode(t, err, "NOT_FOUND")
+}
+
+func TestCountByProductIDRejectsEmptyID(t *testing.T) {
+	uc, _ := newTestUsecase()
+
+	_, err := uc.CountByProductID(context.Background(), "")
+	assertCode(t, err, "INVALID_INPUT")
+}
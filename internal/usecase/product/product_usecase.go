@@ -7,10 +7,47 @@ import (
 )
 
 type ProductUsecase interface {
-	// TODO: РЕАЛИЗОВАТЬ СОЗДАНИЕ ПРОДУКТА В КАТЕГОРИИ
-	Create(ctx context.Context, product *dto.CreateProductRequest, categoryID string) (*dto.ProductResponse, error)
+	// Create adds the product to categoryID. A category_id in the body
+	// must match it.
+	Create(ctx context.Context, product *dto.CreateProductRequest, categoryID, sellerID string) (*dto.ProductResponse, error)
 	GetByTitle(ctx context.Context, title string) (*entity.Product, error)
-	Update(ctx context.Context, product *dto.UpdateProductRequest, id string) (*dto.ProductResponse, error)
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, categoryID string, limit, offset int) ([]dto.ProductResponse, error)
+	// Update and Delete return FORBIDDEN unless sellerID owns the product.
+	Update(ctx context.Context, product *dto.UpdateProductRequest, id, sellerID string) (*dto.ProductResponse, error)
+	// Delete follows the configured delete policy, soft by default.
+	Delete(ctx context.Context, id, sellerID string) error
+	// HardDelete removes the product row regardless of the policy. Only
+	// admins may call it.
+	HardDelete(ctx context.Context, id string) error
+	SetActiveByCategory(ctx context.Context, categoryID string, active bool) (int64, error)
+	// List shows active products unless filter.IsActive says otherwise.
+	// Inactive products are listed for admins, and for sellers limited to
+	// their own; anyone else gets FORBIDDEN.
+	List(ctx context.Context, categoryID string, filter dto.ProductListFilter, sort dto.ProductSort, limit, offset int) (*dto.PaginatedResponse[dto.ProductResponse], error)
+	// SetFeatured is allowed for admins, and for the owning seller when
+	// Product.FeaturedBy is "seller".
+	SetFeatured(ctx context.Context, id string, featured bool) error
+	ListFeatured(ctx context.Context, limit, offset int) (*dto.PaginatedResponse[dto.ProductResponse], error)
+	// ListPopular returns the most viewed products.
+	ListPopular(ctx context.Context, limit, offset int) ([]dto.ProductResponse, error)
+	// ListSellerProducts lists the seller's own products. An empty status
+	// lists every moderation status.
+	ListSellerProducts(ctx context.Context, sellerID, status string, limit, offset int) ([]dto.ProductResponse, error)
+	// ExportSellerProducts passes every product of the seller to emit, one
+	// page at a time, and stops at the first error emit returns.
+	ExportSellerProducts(ctx context.Context, sellerID string, emit func([]dto.ProductExportRow) error) error
+	// ImportSellerProducts creates the valid rows in one transaction and
+	// reports why each invalid row was skipped.
+	ImportSellerProducts(ctx context.Context, sellerID string, rows []dto.ImportProductRow) (*dto.ImportProductsResponse, error)
+	ListPendingModeration(ctx context.Context, limit, offset int) ([]dto.ProductResponse, error)
+	// Approve publishes a product; Reject hides it with a reason.
+	Approve(ctx context.Context, id string) error
+	Reject(ctx context.Context, id string, req dto.RejectProductRequest) error
+	Search(ctx context.Context, query string, inStockOnly bool, limit, offset int) ([]dto.ProductResponse, error)
+	GetCard(ctx context.Context, id string) (*dto.ProductCard, error)
+	// GetByID returns NOT_FOUND for missing and for inactive products. A
+	// found product has the view counted.
+	GetByID(ctx context.Context, id string) (*entity.Product, error)
+	SellerStats(ctx context.Context, sellerID string) (*dto.SellerProductStats, error)
+	CategoryStats(ctx context.Context, categoryID string) (*dto.CategoryStatsResponse, error)
+	CheckAvailability(ctx context.Context, ids []string) ([]dto.ProductAvailability, error)
 }
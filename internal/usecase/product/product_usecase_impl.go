@@ -3,33 +3,81 @@ package usecase
 import (
 	"context"
 	errorsLib "errors"
+	"fmt"
+	"marketplace/internal/adapter/postgres/category"
 	"marketplace/internal/adapter/postgres/product"
 	"marketplace/internal/entity"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
 	"marketplace/pkg/dto"
 	"marketplace/pkg/errors"
+	"marketplace/pkg/money"
+	"math"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// userTypeSeller is the caller type allowed to create products.
+	userTypeSeller = "seller"
+	userTypeAdmin  = "admin"
+)
+
+// maxAvailabilityIDs bounds the number of products checked in one request.
+const maxAvailabilityIDs = 100
+
+// maxSearchQueryLength bounds search queries in characters.
+const maxSearchQueryLength = 100
+
+// exportPageSize is how many products an export reads per query.
+const exportPageSize = 200
+
+const (
+	TitleScopeGlobal   = "global"
+	TitleScopeSeller   = "seller"
+	TitleScopeCategory = "category"
+)
+
+var _ ProductUsecase = (*productUsecase)(nil)
+
 type productUsecase struct {
-	adapter  product.ProductRepository
-	logger   *logrus.Logger
-	validate *validator.Validate
+	adapter    product.ProductRepository
+	categories category.CategoryRepository
+	logger     *logrus.Logger
+	validate   *validator.Validate
+	cfg        config.ProductConfig
+	// deletePolicy is config.DeleteSoft or config.DeleteHard.
+	deletePolicy string
+	prices       money.Formatter
+	views        *ViewCounter
 }
 
-func NewProductUsecase(adapter product.ProductRepository, logger *logrus.Logger, validate *validator.Validate) *productUsecase {
+func NewProductUsecase(adapter product.ProductRepository, categories category.CategoryRepository, logger *logrus.Logger, validate *validator.Validate, cfg config.ProductConfig, deletePolicy string, prices money.Formatter, views *ViewCounter) *productUsecase {
+	switch cfg.TitleUniqueScope {
+	case TitleScopeGlobal, TitleScopeSeller, TitleScopeCategory:
+	default:
+		logger.Warnf("Invalid title unique scope %q, using %q", cfg.TitleUniqueScope, TitleScopeGlobal)
+		cfg.TitleUniqueScope = TitleScopeGlobal
+	}
+
 	return &productUsecase{
-		adapter:  adapter,
-		logger:   logger,
-		validate: validate,
+		adapter:      adapter,
+		categories:   categories,
+		logger:       logger,
+		validate:     validate,
+		cfg:          cfg,
+		deletePolicy: deletePolicy,
+		prices:       prices,
+		views:        views,
 	}
 }
 
-// TODO: РЕАЛИЗОВАТЬ СОЗДАНИЕ ПРОДУКТА В КАТЕГОРИИ
-func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequest, categoryID string) (*dto.ProductResponse, error) {
+func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequest, categoryID, sellerID string) (*dto.ProductResponse, error) {
 	if req == nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
@@ -38,7 +86,41 @@ func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequ
 		return nil, errors.NewAppError("INVALID_INPUT", "bad request", nil)
 	}
 
-	if err := uc.validate.StructCtx(ctx, &req); err != nil {
+	if sellerID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "create",
+		}).Warn("Empty seller id")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+
+	if categoryID == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "category id is empty", nil)
+	}
+	if req.CategoryID != "" && req.CategoryID != categoryID {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":        "create",
+			"category_id":      categoryID,
+			"body_category_id": req.CategoryID,
+		}).Warn("Category mismatch")
+		return nil, errors.NewAppError("INVALID_INPUT", "category_id does not match the URL", nil)
+	}
+	req.CategoryID = categoryID
+
+	if uc.cfg.SellerOnlyCreate {
+		if caller := authctx.FromContext(ctx); caller.Type != userTypeSeller {
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "create",
+				"user_id":   caller.ID,
+				"user_type": caller.Type,
+			}).Warn("Non-seller tried to create product")
+			return nil, errors.NewAppError("FORBIDDEN", "only sellers can create products", nil)
+		}
+	}
+
+	var normalizedTitle string
+	req.Title, normalizedTitle = normalizeTitle(req.Title)
+
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
 		var validatorErrs validator.ValidationErrors
 		if errorsLib.As(err, &validatorErrs) {
 			var msgs []string
@@ -57,7 +139,32 @@ func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequ
 		return nil, errors.NewAppError("VALIDATE_ERR", "unexpected validation error", err)
 	}
 
-	existing, err := uc.adapter.GetByTitle(ctx, req.Title)
+	if err := uc.checkPrice(req.Price); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "create",
+			"price":     req.Price,
+		}).Warn("Price out of bounds")
+		return nil, err
+	}
+
+	if err := uc.checkDescription(req.Description); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "create",
+			"length":    utf8.RuneCountInString(req.Description),
+		}).Warn("Description too long")
+		return nil, err
+	}
+
+	if err := uc.checkCategory(ctx, req.CategoryID); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":   "create",
+			"category_id": req.CategoryID,
+			"error":       err,
+		}).Warn("Invalid category")
+		return nil, err
+	}
+
+	existing, err := uc.findDuplicate(ctx, normalizedTitle, sellerID, req.CategoryID)
 	if err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
@@ -70,22 +177,36 @@ func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequ
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
 			"title":     req.Title,
+			"scope":     uc.cfg.TitleUniqueScope,
 		}).Warn("Product already exists")
-		return nil, errors.NewAppError("BUSINESS_ERR", "product already exists", nil)
+		return nil, errors.NewAppError("DUPLICATE", "product already exists", nil)
 	}
 
 	p := entity.Product{
-		ID:         uuid.NewString(),
-		SellerID:   req.SellerID,
-		CategoryID: req.CategoryID,
-		Title:      req.Title,
-		Price:      req.Price,
-		CreatedAt:  time.Now().UTC(),
-		UpdatedAt:  time.Now().UTC(),
-		IsActive:   true,
+		ID:               uuid.NewString(),
+		SellerID:         sellerID,
+		CategoryID:       req.CategoryID,
+		Title:            req.Title,
+		TitleNormalized:  normalizedTitle,
+		TitleKey:         uc.titleKey(normalizedTitle, sellerID, req.CategoryID),
+		Description:      req.Description,
+		Price:            req.Price,
+		Stock:            req.Stock,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+		IsActive:         true,
+		Version:          1,
+		ModerationStatus: entity.ModerationApproved,
+	}
+	if uc.cfg.RequireModeration {
+		p.IsActive = false
+		p.ModerationStatus = entity.ModerationPending
 	}
 
 	if err := uc.adapter.Create(ctx, &p); err != nil {
+		if isDuplicate(err) {
+			return nil, errors.NewAppError("DUPLICATE", "product already exists", err)
+		}
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "create",
 			"error":     err,
@@ -95,10 +216,15 @@ func (uc *productUsecase) Create(ctx context.Context, req *dto.CreateProductRequ
 	}
 
 	resp := dto.ProductResponse{
-		SellerID:   p.SellerID,
-		CategoryID: p.CategoryID,
-		Title:      p.Title,
-		Price:      p.Price,
+		ID:               p.ID,
+		SellerID:         p.SellerID,
+		CategoryID:       p.CategoryID,
+		Title:            p.Title,
+		Price:            p.Price,
+		PriceDisplay:     uc.prices.Format(p.Price),
+		Stock:            p.Stock,
+		Version:          p.Version,
+		ModerationStatus: p.ModerationStatus,
 	}
 
 	uc.logger.WithFields(logrus.Fields{
@@ -119,8 +245,9 @@ func (uc *productUsecase) GetByTitle(ctx context.Context, title string) (*entity
 		return nil, errors.NewAppError("INVALID_INPUT", "empty title", nil)
 	}
 
-	product, err := uc.adapter.GetByTitle(ctx, title)
-	if err != nil {
+	_, normalizedTitle := normalizeTitle(title)
+	product, err := uc.adapter.GetByTitle(ctx, normalizedTitle)
+	if err != nil && !errorsLib.Is(err, errors.ErrNotFound) {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "get_by_title",
 			"title":     title,
@@ -128,6 +255,14 @@ func (uc *productUsecase) GetByTitle(ctx context.Context, title string) (*entity
 		}).Warn("Failed get by title")
 		return nil, errors.NewAppError("GET_ERROR", "failed get product by title", err)
 	}
+	// Inactive products are hidden from the public lookup.
+	if err != nil || !product.IsActive {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "get_by_title",
+			"title":     title,
+		}).Warn("Product not found")
+		return nil, errors.NewAppError("NOT_FOUND", "product not found", err)
+	}
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation": "get_by_title",
@@ -137,7 +272,7 @@ func (uc *productUsecase) GetByTitle(ctx context.Context, title string) (*entity
 	return product, nil
 }
 
-func (uc *productUsecase) Update(ctx context.Context, req *dto.UpdateProductRequest, id string) (*dto.ProductResponse, error) {
+func (uc *productUsecase) Update(ctx context.Context, req *dto.UpdateProductRequest, id, sellerID string) (*dto.ProductResponse, error) {
 	if req == nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "update",
@@ -146,7 +281,10 @@ func (uc *productUsecase) Update(ctx context.Context, req *dto.UpdateProductRequ
 		return nil, errors.NewAppError("INVALID_INPUT", "bad request", nil)
 	}
 
-	if err := uc.validate.StructCtx(ctx, &req); err != nil {
+	var normalizedTitle string
+	req.Title, normalizedTitle = normalizeTitle(req.Title)
+
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
 		var validatorErrs validator.ValidationErrors
 		if errorsLib.As(err, &validatorErrs) {
 			var msgs []string
@@ -165,38 +303,111 @@ func (uc *productUsecase) Update(ctx context.Context, req *dto.UpdateProductRequ
 		return nil, errors.NewAppError("VALIDATE_ERR", "unexpected validation error", err)
 	}
 
-	if _, err := uc.adapter.GetByID(ctx, id); err != nil {
+	if err := uc.checkPrice(req.Price); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"price":     req.Price,
+		}).Warn("Price out of bounds")
+		return nil, err
+	}
+
+	if err := uc.checkDescription(req.Description); err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"length":    utf8.RuneCountInString(req.Description),
+		}).Warn("Description too long")
+		return nil, err
+	}
+
+	current, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "update",
 			"id":        id,
 			"error":     err,
-		}).Warn("User not found")
-		return nil, errors.NewAppError("NOT_FOUND", "user not found", err)
+		}).Warn("Failed get product")
+		return nil, errors.NewAppError("GET_ERROR", "failed get product", err)
+	}
+	if current.SellerID != sellerID {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"id":        id,
+			"seller_id": sellerID,
+		}).Warn("Product belongs to another seller")
+		return nil, errors.NewAppError("FORBIDDEN", "product belongs to another seller", nil)
+	}
+	if req.Version > 0 && req.Version != current.Version {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"id":        id,
+			"version":   req.Version,
+			"current":   current.Version,
+		}).Warn("Stale product version")
+		return nil, errors.NewAppError("CONFLICT", "product was modified, reload and retry", errors.ErrConflict)
 	}
 
-	p := entity.Product{
-		ID:         req.ID,
-		CategoryID: req.CategoryID,
-		Title:      req.Title,
-		Price:      req.Price,
-		UpdatedAt:  time.Now().UTC(),
+	if req.CategoryID != current.CategoryID {
+		if err := uc.checkCategory(ctx, req.CategoryID); err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"operation":   "update",
+				"category_id": req.CategoryID,
+				"error":       err,
+			}).Warn("Invalid category")
+			return nil, err
+		}
 	}
 
+	existing, err := uc.findDuplicate(ctx, normalizedTitle, current.SellerID, req.CategoryID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"error":     err,
+			"title":     req.Title,
+		}).Warn("Failed update product")
+		return nil, errors.NewAppError("CHECK_ERR", "failed check product", err)
+	}
+	if existing != nil && existing.ID != id {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "update",
+			"title":     req.Title,
+			"scope":     uc.cfg.TitleUniqueScope,
+		}).Warn("Product already exists")
+		return nil, errors.NewAppError("DUPLICATE", "product already exists", nil)
+	}
+
+	// Start from the stored product so the fields the request cannot change
+	// survive the update.
+	p := *current
+	p.CategoryID = req.CategoryID
+	p.Title = req.Title
+	p.TitleNormalized = normalizedTitle
+	p.TitleKey = uc.titleKey(normalizedTitle, current.SellerID, req.CategoryID)
+	p.Description = req.Description
+	p.Price = req.Price
+	p.Stock = req.Stock
+	p.UpdatedAt = time.Now().UTC()
+	p.Version = req.Version
+
 	if err := uc.adapter.Update(ctx, &p); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "update",
 			"req":       req,
 			"error":     err,
 		}).Warn("Failed update product")
+		if errorsLib.Is(err, errors.ErrConflict) {
+			return nil, errors.NewAppError("CONFLICT", "product was modified, reload and retry", err)
+		}
+		if isDuplicate(err) {
+			return nil, errors.NewAppError("DUPLICATE", "product already exists", err)
+		}
 		return nil, errors.NewAppError("UPDATE_ERR", "failed update product", err)
 	}
+	p.Version = current.Version + 1
 
-	resp := dto.ProductResponse{
-		SellerID:   p.SellerID,
-		CategoryID: p.CategoryID,
-		Title:      p.Title,
-		Price:      p.Price,
-	}
+	resp := uc.toProductResponse(p)
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation": "update",
@@ -206,7 +417,7 @@ func (uc *productUsecase) Update(ctx context.Context, req *dto.UpdateProductRequ
 	return &resp, nil
 }
 
-func (uc *productUsecase) Delete(ctx context.Context, id string) error {
+func (uc *productUsecase) Delete(ctx context.Context, id, sellerID string) error {
 	if id == "" {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "delete",
@@ -215,10 +426,36 @@ func (uc *productUsecase) Delete(ctx context.Context, id string) error {
 		return errors.NewAppError("INVALID_INPUT", "empty id string", nil)
 	}
 
-	if err := uc.adapter.Delete(ctx, id); err != nil {
+	current, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "delete",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get product")
+		return errors.NewAppError("GET_ERROR", "failed get product", err)
+	}
+	if current.SellerID != sellerID {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "delete",
+			"id":        id,
+			"seller_id": sellerID,
+		}).Warn("Product belongs to another seller")
+		return errors.NewAppError("FORBIDDEN", "product belongs to another seller", nil)
+	}
+
+	deleteFn := uc.adapter.Delete
+	if uc.deletePolicy == config.DeleteHard {
+		deleteFn = uc.adapter.HardDelete
+	}
+	if err := deleteFn(ctx, id); err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation": "delete",
 			"id":        id,
+			"policy":    uc.deletePolicy,
 			"error":     err,
 		}).Warn("Failed delete product")
 		return errors.NewAppError("DELETE_ERR", "failed delete product", err)
@@ -227,12 +464,668 @@ func (uc *productUsecase) Delete(ctx context.Context, id string) error {
 	uc.logger.WithFields(logrus.Fields{
 		"operation": "delete",
 		"id":        id,
+		"policy":    uc.deletePolicy,
 	}).Info("Product deleted successfully")
 
 	return nil
 }
 
-func (uc *productUsecase) List(ctx context.Context, categoryID string, limit, offset int) ([]dto.ProductResponse, error) {
+func (uc *productUsecase) HardDelete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.NewAppError("INVALID_INPUT", "empty id string", nil)
+	}
+
+	caller := authctx.FromContext(ctx)
+	if caller.Type != userTypeAdmin {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "hard_delete",
+			"id":        id,
+			"user_id":   caller.ID,
+			"user_type": caller.Type,
+		}).Warn("Non-admin tried to hard delete product")
+		return errors.NewAppError("FORBIDDEN", "only admins can permanently delete products", nil)
+	}
+
+	if err := uc.adapter.HardDelete(ctx, id); err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "hard_delete",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed hard delete product")
+		return errors.NewAppError("DELETE_ERR", "failed delete product", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "hard_delete",
+		"id":        id,
+		"admin_id":  caller.ID,
+	}).Info("Product permanently deleted")
+
+	return nil
+}
+
+func (uc *productUsecase) SetActiveByCategory(ctx context.Context, categoryID string, active bool) (int64, error) {
+	if categoryID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":   "set_active_by_category",
+			"category_id": categoryID,
+		}).Warn("Invalid input")
+		return 0, errors.NewAppError("INVALID_INPUT", "empty category id", nil)
+	}
+
+	updated, err := uc.adapter.SetActiveByCategory(ctx, categoryID, active)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":   "set_active_by_category",
+			"category_id": categoryID,
+			"error":       err,
+		}).Warn("Failed set products active flag")
+		return 0, errors.NewAppError("UPDATE_ERR", "failed update products", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation":   "set_active_by_category",
+		"category_id": categoryID,
+		"is_active":   active,
+		"updated":     updated,
+	}).Info("Products active flag updated")
+
+	return updated, nil
+}
+
+func (uc *productUsecase) SetFeatured(ctx context.Context, id string, featured bool) error {
+	if id == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "set_featured",
+			"id":        id,
+		}).Warn("Invalid input")
+		return errors.NewAppError("INVALID_INPUT", "empty product id", nil)
+	}
+
+	caller := authctx.FromContext(ctx)
+	switch {
+	case caller.Type == userTypeAdmin:
+	case caller.Type == userTypeSeller && uc.cfg.FeaturedBy == userTypeSeller:
+		current, err := uc.adapter.GetByID(ctx, id)
+		if err != nil {
+			if errorsLib.Is(err, errors.ErrNotFound) {
+				return errors.NewAppError("NOT_FOUND", "product not found", err)
+			}
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "set_featured",
+				"id":        id,
+				"error":     err,
+			}).Warn("Failed get product")
+			return errors.NewAppError("GET_ERROR", "failed get product", err)
+		}
+		if current.SellerID != caller.ID {
+			return errors.NewAppError("FORBIDDEN", "product belongs to another seller", nil)
+		}
+	default:
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "set_featured",
+			"user_id":   caller.ID,
+			"user_type": caller.Type,
+		}).Warn("Not allowed to feature products")
+		return errors.NewAppError("FORBIDDEN", "not allowed to feature products", nil)
+	}
+
+	if err := uc.adapter.SetFeatured(ctx, id, featured); err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "set_featured",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed set featured")
+		return errors.NewAppError("UPDATE_ERR", "failed update product", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "set_featured",
+		"id":        id,
+		"featured":  featured,
+		"user_id":   caller.ID,
+	}).Info("Product featured flag updated")
+
+	return nil
+}
+
+func (uc *productUsecase) ListSellerProducts(ctx context.Context, sellerID, status string, limit, offset int) ([]dto.ProductResponse, error) {
+	if sellerID == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+	switch status {
+	case "", entity.ModerationApproved, entity.ModerationPending, entity.ModerationRejected:
+	default:
+		return nil, errors.NewAppError("INVALID_INPUT", "status must be approved, pending or rejected", nil)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	products, err := uc.adapter.ListBySeller(ctx, sellerID, status, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_seller_products",
+			"seller_id": sellerID,
+			"status":    status,
+			"error":     err,
+		}).Warn("Failed list seller products")
+		return nil, errors.NewAppError("LIST_ERR", "failed list seller products", err)
+	}
+
+	list := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		list = append(list, uc.toProductResponse(p))
+	}
+
+	return list, nil
+}
+
+func (uc *productUsecase) ExportSellerProducts(ctx context.Context, sellerID string, emit func([]dto.ProductExportRow) error) error {
+	if sellerID == "" {
+		return errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+
+	var afterID string
+	exported := 0
+	for {
+		products, err := uc.adapter.ListBySellerAfter(ctx, sellerID, afterID, exportPageSize)
+		if err != nil {
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "export_seller_products",
+				"seller_id": sellerID,
+				"after_id":  afterID,
+				"error":     err,
+			}).Warn("Failed list seller products")
+			return errors.NewAppError("LIST_ERR", "failed list seller products", err)
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		rows := make([]dto.ProductExportRow, 0, len(products))
+		for _, p := range products {
+			rows = append(rows, dto.ProductExportRow{
+				ID:               p.ID,
+				CategoryID:       p.CategoryID,
+				Title:            p.Title,
+				Description:      p.Description,
+				Price:            p.Price,
+				Stock:            p.Stock,
+				IsActive:         p.IsActive,
+				ModerationStatus: p.ModerationStatus,
+				CreatedAt:        p.CreatedAt,
+				UpdatedAt:        p.UpdatedAt,
+			})
+		}
+		if err := emit(rows); err != nil {
+			return err
+		}
+
+		exported += len(products)
+		if len(products) < exportPageSize {
+			break
+		}
+		afterID = products[len(products)-1].ID
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "export_seller_products",
+		"seller_id": sellerID,
+		"exported":  exported,
+	}).Info("Seller products exported")
+
+	return nil
+}
+
+func (uc *productUsecase) ImportSellerProducts(ctx context.Context, sellerID string, rows []dto.ImportProductRow) (*dto.ImportProductsResponse, error) {
+	if sellerID == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+	if limit := uc.cfg.MaxImportRows; limit > 0 && len(rows) > limit {
+		return nil, errors.NewAppError("INVALID_INPUT", fmt.Sprintf("import is limited to %d rows", limit), nil)
+	}
+
+	resp := &dto.ImportProductsResponse{Errors: []dto.ImportRowError{}}
+	reject := func(row int, err error) {
+		msg := err.Error()
+		var appErr *errors.AppError
+		if errorsLib.As(err, &appErr) {
+			msg = appErr.Message()
+		}
+		resp.Errors = append(resp.Errors, dto.ImportRowError{Row: row, Error: msg})
+	}
+
+	categoryErrs := make(map[string]error)
+	seen := make(map[string]bool)
+	products := make([]entity.Product, 0, len(rows))
+	now := time.Now().UTC()
+	for _, row := range rows {
+		req := row.Product
+		var normalizedTitle string
+		req.Title, normalizedTitle = normalizeTitle(req.Title)
+
+		if err := uc.validate.StructCtx(ctx, req); err != nil {
+			var validatorErrs validator.ValidationErrors
+			if errorsLib.As(err, &validatorErrs) {
+				fields := make([]string, 0, len(validatorErrs))
+				for _, e := range validatorErrs {
+					fields = append(fields, e.Field())
+				}
+				reject(row.Row, fmt.Errorf("invalid fields: %s", strings.Join(fields, ", ")))
+				continue
+			}
+			return nil, errors.NewAppError("VALIDATE_ERR", "unexpected validation error", err)
+		}
+		if req.CategoryID == "" {
+			reject(row.Row, fmt.Errorf("category_id is required"))
+			continue
+		}
+		if err := uc.checkPrice(req.Price); err != nil {
+			reject(row.Row, err)
+			continue
+		}
+		if err := uc.checkDescription(req.Description); err != nil {
+			reject(row.Row, err)
+			continue
+		}
+
+		catErr, checked := categoryErrs[req.CategoryID]
+		if !checked {
+			catErr = uc.checkCategory(ctx, req.CategoryID)
+			categoryErrs[req.CategoryID] = catErr
+		}
+		if catErr != nil {
+			reject(row.Row, catErr)
+			continue
+		}
+
+		key := uc.titleKey(normalizedTitle, sellerID, req.CategoryID)
+		if seen[key] {
+			reject(row.Row, fmt.Errorf("duplicate of an earlier row"))
+			continue
+		}
+		existing, err := uc.findDuplicate(ctx, normalizedTitle, sellerID, req.CategoryID)
+		if err != nil {
+			return nil, errors.NewAppError("CHECK_ERR", "failed check product", err)
+		}
+		if existing != nil {
+			reject(row.Row, fmt.Errorf("product already exists"))
+			continue
+		}
+		seen[key] = true
+
+		p := entity.Product{
+			ID:               uuid.NewString(),
+			SellerID:         sellerID,
+			CategoryID:       req.CategoryID,
+			Title:            req.Title,
+			TitleNormalized:  normalizedTitle,
+			TitleKey:         uc.titleKey(normalizedTitle, sellerID, req.CategoryID),
+			Description:      req.Description,
+			Price:            req.Price,
+			Stock:            req.Stock,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			IsActive:         true,
+			Version:          1,
+			ModerationStatus: entity.ModerationApproved,
+		}
+		if uc.cfg.RequireModeration {
+			p.IsActive = false
+			p.ModerationStatus = entity.ModerationPending
+		}
+		products = append(products, p)
+	}
+
+	if err := uc.adapter.CreateMany(ctx, products); err != nil {
+		if isDuplicate(err) {
+			return nil, errors.NewAppError("DUPLICATE", "a product was created concurrently with the same title, retry the import", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "import",
+			"seller_id": sellerID,
+			"count":     len(products),
+			"error":     err,
+		}).Warn("Failed import products")
+		return nil, errors.NewAppError("CREATE_ERR", "failed import products", err)
+	}
+
+	resp.Imported = len(products)
+	resp.Failed = len(resp.Errors)
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "import",
+		"seller_id": sellerID,
+		"imported":  resp.Imported,
+		"failed":    resp.Failed,
+	}).Info("Seller products imported")
+
+	return resp, nil
+}
+
+// titleKey qualifies a normalized title by TitleUniqueScope. Two products
+// clash exactly when their keys are equal.
+func (uc *productUsecase) titleKey(normalizedTitle, sellerID, categoryID string) string {
+	switch uc.cfg.TitleUniqueScope {
+	case TitleScopeSeller:
+		return sellerID + "/" + normalizedTitle
+	case TitleScopeCategory:
+		return categoryID + "/" + normalizedTitle
+	default:
+		return normalizedTitle
+	}
+}
+
+// isDuplicate reports whether the repository rejected a write because the
+// title key is taken, which a concurrent request can cause after
+// findDuplicate passed.
+func isDuplicate(err error) bool {
+	var appErr *errors.AppError
+	return errorsLib.As(err, &appErr) && appErr.Code() == "DUPLICATE"
+}
+
+func (uc *productUsecase) ListPendingModeration(ctx context.Context, limit, offset int) ([]dto.ProductResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	products, err := uc.adapter.ListByModerationStatus(ctx, entity.ModerationPending, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_pending_moderation",
+			"error":     err,
+		}).Warn("Failed list pending products")
+		return nil, errors.NewAppError("LIST_ERR", "failed list pending products", err)
+	}
+
+	list := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		list = append(list, uc.toProductResponse(p))
+	}
+
+	return list, nil
+}
+
+func (uc *productUsecase) Approve(ctx context.Context, id string) error {
+	return uc.moderate(ctx, id, entity.ModerationApproved, "")
+}
+
+func (uc *productUsecase) Reject(ctx context.Context, id string, req dto.RejectProductRequest) error {
+	if err := uc.validate.StructCtx(ctx, req); err != nil {
+		return errors.NewAppError("VALIDATION", "rejection reason is required and at most 500 characters", err)
+	}
+
+	return uc.moderate(ctx, id, entity.ModerationRejected, strings.TrimSpace(req.Reason))
+}
+
+func (uc *productUsecase) moderate(ctx context.Context, id, status, reason string) error {
+	if id == "" {
+		return errors.NewAppError("INVALID_INPUT", "empty product id", nil)
+	}
+
+	if err := uc.adapter.SetModeration(ctx, id, status, reason); err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "moderate",
+			"id":        id,
+			"status":    status,
+			"error":     err,
+		}).Warn("Failed set moderation status")
+		return errors.NewAppError("UPDATE_ERR", "failed update product", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "moderate",
+		"id":        id,
+		"status":    status,
+		"admin_id":  authctx.FromContext(ctx).ID,
+	}).Info("Product moderated")
+
+	return nil
+}
+
+func (uc *productUsecase) ListFeatured(ctx context.Context, limit, offset int) (*dto.PaginatedResponse[dto.ProductResponse], error) {
+	if limit <= 0 || limit > 100 {
+		limit = 40
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	products, total, err := uc.adapter.ListFeatured(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_featured",
+			"error":     err,
+		}).Warn("Failed list featured products")
+		return nil, errors.NewAppError("LIST_ERR", "failed list featured products", err)
+	}
+
+	list := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		list = append(list, uc.toProductResponse(p))
+	}
+
+	return &dto.PaginatedResponse[dto.ProductResponse]{
+		Items:  list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func (uc *productUsecase) ListPopular(ctx context.Context, limit, offset int) ([]dto.ProductResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 40
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	products, err := uc.adapter.ListPopular(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "list_popular",
+			"error":     err,
+		}).Warn("Failed list popular products")
+		return nil, errors.NewAppError("LIST_ERR", "failed list popular products", err)
+	}
+
+	list := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		list = append(list, uc.toProductResponse(p))
+	}
+
+	return list, nil
+}
+
+func (uc *productUsecase) Search(ctx context.Context, query string, inStockOnly bool, limit, offset int) ([]dto.ProductResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "search query is required", nil)
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, errors.NewAppError("INVALID_INPUT", fmt.Sprintf("search query must be at most %d characters", maxSearchQueryLength), nil)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	products, err := uc.adapter.Search(ctx, query, inStockOnly, limit, offset)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "search",
+			"query":     query,
+			"error":     err,
+		}).Warn("Failed search products")
+		return nil, errors.NewAppError("LIST_ERR", "failed search products", err)
+	}
+
+	list := make([]dto.ProductResponse, 0, len(products))
+	for _, p := range products {
+		list = append(list, uc.toProductResponse(p))
+	}
+
+	return list, nil
+}
+
+func (uc *productUsecase) GetCard(ctx context.Context, id string) (*dto.ProductCard, error) {
+	if id == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "get_card",
+			"id":        id,
+		}).Warn("Invalid input: empty id")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty product id", nil)
+	}
+
+	card, err := uc.adapter.GetCard(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "get_card",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get product card")
+		return nil, errors.NewAppError("GET_ERROR", "failed get product card", err)
+	}
+
+	return &dto.ProductCard{
+		ID:            card.ID,
+		Title:         card.Title,
+		Price:         card.Price,
+		PriceDisplay:  uc.prices.Format(card.Price),
+		CategoryName:  card.CategoryName,
+		SellerCompany: dto.NullString(card.SellerCompany),
+	}, nil
+}
+
+func (uc *productUsecase) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	if id == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "get_by_id",
+			"id":        id,
+		}).Warn("Invalid input: empty id")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty product id", nil)
+	}
+
+	p, err := uc.adapter.GetByID(ctx, id)
+	if err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return nil, errors.NewAppError("NOT_FOUND", "product not found", err)
+		}
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "get_by_id",
+			"id":        id,
+			"error":     err,
+		}).Warn("Failed get product")
+		return nil, errors.NewAppError("GET_ERROR", "failed get product", err)
+	}
+	if !p.IsActive {
+		return nil, errors.NewAppError("NOT_FOUND", "product not found", nil)
+	}
+
+	uc.views.Record(p.ID)
+
+	return p, nil
+}
+
+func (uc *productUsecase) SellerStats(ctx context.Context, sellerID string) (*dto.SellerProductStats, error) {
+	if sellerID == "" {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "seller_stats",
+			"seller_id": sellerID,
+		}).Warn("Invalid input")
+		return nil, errors.NewAppError("INVALID_INPUT", "empty seller id", nil)
+	}
+
+	counts, err := uc.adapter.CountBySellerStatus(ctx, sellerID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "seller_stats",
+			"seller_id": sellerID,
+			"error":     err,
+		}).Warn("Failed count seller products")
+		return nil, errors.NewAppError("GET_ERR", "failed count products", err)
+	}
+
+	return &dto.SellerProductStats{
+		Active:   counts.Active,
+		Inactive: counts.Inactive,
+		Total:    counts.Active + counts.Inactive,
+	}, nil
+}
+
+func (uc *productUsecase) CategoryStats(ctx context.Context, categoryID string) (*dto.CategoryStatsResponse, error) {
+	if categoryID == "" {
+		return nil, errors.NewAppError("INVALID_INPUT", "category id is empty", nil)
+	}
+
+	exists, err := uc.categories.Exists(ctx, "id", categoryID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":   "category_stats",
+			"category_id": categoryID,
+			"error":       err,
+		}).Warn("Failed check category exists")
+		return nil, errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+	if !exists {
+		return nil, errors.NewAppError("NOT_FOUND", "category not found", nil)
+	}
+
+	stats, err := uc.adapter.StatsByCategory(ctx, categoryID)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation":   "category_stats",
+			"category_id": categoryID,
+			"error":       err,
+		}).Warn("Failed compute category stats")
+		return nil, errors.NewAppError("STATS_ERR", "failed compute category stats", err)
+	}
+
+	resp := &dto.CategoryStatsResponse{
+		CategoryID:   categoryID,
+		ProductCount: stats.Count,
+		AvgPrice:     math.Round(stats.AvgPrice*100) / 100,
+		MinPrice:     stats.MinPrice,
+		MaxPrice:     stats.MaxPrice,
+	}
+	if stats.Count > 0 {
+		resp.ActiveRatio = float64(stats.Active) / float64(stats.Count)
+	}
+
+	return resp, nil
+}
+
+// listSorts maps the public sort values to the repository order.
+var listSorts = map[dto.ProductSort]product.Sort{
+	dto.ProductSortNewest:    product.SortNewest,
+	dto.ProductSortPriceAsc:  product.SortPriceAsc,
+	dto.ProductSortPriceDesc: product.SortPriceDesc,
+	dto.ProductSortTitle:     product.SortTitle,
+}
+
+func (uc *productUsecase) List(ctx context.Context, categoryID string, filter dto.ProductListFilter, sort dto.ProductSort, limit, offset int) (*dto.PaginatedResponse[dto.ProductResponse], error) {
 	if categoryID == "" {
 		uc.logger.WithFields(logrus.Fields{
 			"operation":   "list",
@@ -257,7 +1150,45 @@ func (uc *productUsecase) List(ctx context.Context, categoryID string, limit, of
 		offset = 0
 	}
 
-	products, err := uc.adapter.List(ctx, categoryID, limit, offset)
+	if (filter.MinPrice != nil && *filter.MinPrice < 0) || (filter.MaxPrice != nil && *filter.MaxPrice < 0) {
+		return nil, errors.NewAppError("INVALID_INPUT", "price filter must not be negative", nil)
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, errors.NewAppError("INVALID_INPUT", "min_price must not exceed max_price", nil)
+	}
+
+	if sort == "" {
+		sort = dto.ProductSortNewest
+	}
+	repoSort, ok := listSorts[sort]
+	if !ok {
+		return nil, errors.NewAppError("VALIDATION", fmt.Sprintf("unknown sort %q", sort), nil)
+	}
+
+	repoFilter := product.ListFilter{
+		InStockOnly: filter.InStockOnly,
+		MinPrice:    filter.MinPrice,
+		MaxPrice:    filter.MaxPrice,
+		IsActive:    filter.IsActive,
+		Sort:        repoSort,
+	}
+
+	if filter.IsActive != nil && !*filter.IsActive {
+		switch caller := authctx.FromContext(ctx); caller.Type {
+		case userTypeAdmin:
+		case userTypeSeller:
+			repoFilter.SellerID = caller.ID
+		default:
+			uc.logger.WithFields(logrus.Fields{
+				"operation": "list",
+				"user_id":   caller.ID,
+				"user_type": caller.Type,
+			}).Warn("Non-seller tried to list inactive products")
+			return nil, errors.NewAppError("FORBIDDEN", "only admins and sellers can list inactive products", nil)
+		}
+	}
+
+	products, total, err := uc.adapter.List(ctx, categoryID, repoFilter, limit, offset)
 	if err != nil {
 		uc.logger.WithFields(logrus.Fields{
 			"operation":   "list",
@@ -267,22 +1198,165 @@ func (uc *productUsecase) List(ctx context.Context, categoryID string, limit, of
 		return nil, errors.NewAppError("LIST_ERR", "failed list products", err)
 	}
 
-	var list []dto.ProductResponse
+	list := make([]dto.ProductResponse, 0, len(products))
 	for _, p := range products {
-		dtoProduct := dto.ProductResponse{
-			SellerID:   p.SellerID,
-			CategoryID: p.CategoryID,
-			Title:      p.Title,
-			Price:      p.Price,
-		}
-		list = append(list, dtoProduct)
+		list = append(list, uc.toProductResponse(p))
 	}
 
 	uc.logger.WithFields(logrus.Fields{
 		"operation":   "list",
 		"category_id": categoryID,
 		"list_count":  len(list),
+		"total":       total,
 	}).Info("Products successfully listed by category")
 
-	return list, nil
+	return &dto.PaginatedResponse[dto.ProductResponse]{
+		Items:  list,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func (uc *productUsecase) CheckAvailability(ctx context.Context, ids []string) ([]dto.ProductAvailability, error) {
+	if len(ids) == 0 || len(ids) > maxAvailabilityIDs {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "check_availability",
+			"count":     len(ids),
+		}).Warn("Invalid input")
+		return nil, errors.NewAppError("INVALID_INPUT", fmt.Sprintf("between 1 and %d product ids are required", maxAvailabilityIDs), nil)
+	}
+
+	products, err := uc.adapter.GetByIDs(ctx, ids)
+	if err != nil {
+		uc.logger.WithFields(logrus.Fields{
+			"operation": "check_availability",
+			"count":     len(ids),
+			"error":     err,
+		}).Warn("Failed get products by ids")
+		return nil, errors.NewAppError("GET_ERROR", "failed get products", err)
+	}
+
+	byID := make(map[string]entity.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	result := make([]dto.ProductAvailability, 0, len(ids))
+	for _, id := range ids {
+		item := dto.ProductAvailability{ProductID: id}
+		if p, ok := byID[id]; ok {
+			item.Found = true
+			item.IsActive = p.IsActive
+			item.Stock = p.Stock
+			item.Available = p.IsActive && p.Stock > 0
+		}
+		result = append(result, item)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"operation": "check_availability",
+		"requested": len(ids),
+		"found":     len(products),
+	}).Info("Product availability checked")
+
+	return result, nil
+}
+
+// findDuplicate looks for a product with the same normalized title within the
+// configured uniqueness scope. It returns (nil, nil) when the title is free.
+func (uc *productUsecase) findDuplicate(ctx context.Context, normalizedTitle, sellerID, categoryID string) (*entity.Product, error) {
+	var (
+		existing *entity.Product
+		err      error
+	)
+	switch uc.cfg.TitleUniqueScope {
+	case TitleScopeSeller:
+		existing, err = uc.adapter.GetByTitleAndSeller(ctx, normalizedTitle, sellerID)
+	case TitleScopeCategory:
+		existing, err = uc.adapter.GetByTitleAndCategory(ctx, normalizedTitle, categoryID)
+	default:
+		existing, err = uc.adapter.GetByTitle(ctx, normalizedTitle)
+	}
+	if errorsLib.Is(err, errors.ErrNotFound) {
+		return nil, nil
+	}
+
+	return existing, err
+}
+
+func (uc *productUsecase) toProductResponse(p entity.Product) dto.ProductResponse {
+	resp := dto.ProductResponse{
+		ID:               p.ID,
+		SellerID:         p.SellerID,
+		CategoryID:       p.CategoryID,
+		Title:            p.Title,
+		Price:            p.Price,
+		PriceDisplay:     uc.prices.Format(p.Price),
+		Stock:            p.Stock,
+		Version:          p.Version,
+		Featured:         p.Featured,
+		ModerationStatus: p.ModerationStatus,
+		ModerationReason: p.ModerationReason,
+		ViewCount:        p.ViewCount,
+	}
+	if p.PrimaryImageURL != nil {
+		resp.PrimaryImageURL = *p.PrimaryImageURL
+	}
+	return resp
+}
+
+// checkCategory requires the category to exist and, with
+// LeafCategoriesOnly, to have no subcategories.
+func (uc *productUsecase) checkCategory(ctx context.Context, categoryID string) error {
+	if _, err := uc.categories.GetByID(ctx, categoryID); err != nil {
+		if errorsLib.Is(err, errors.ErrNotFound) {
+			return errors.NewAppError("NOT_FOUND", "category not found", err)
+		}
+		return errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+
+	if !uc.cfg.LeafCategoriesOnly {
+		return nil
+	}
+
+	children, err := uc.categories.ListChildren(ctx, categoryID)
+	if err != nil {
+		return errors.NewAppError("CHECK_ERR", "failed check category", err)
+	}
+	if len(children) > 0 {
+		return errors.NewAppError("INVALID_INPUT", "products can only be added to leaf categories", nil)
+	}
+
+	return nil
+}
+
+// checkPrice enforces the configured price bounds; a zero bound is not
+// enforced.
+func (uc *productUsecase) checkPrice(price float64) error {
+	if uc.cfg.MinPrice > 0 && price < uc.cfg.MinPrice {
+		return errors.NewAppError("INVALID_INPUT", fmt.Sprintf("price must be at least %.2f", uc.cfg.MinPrice), nil)
+	}
+	if uc.cfg.MaxPrice > 0 && price > uc.cfg.MaxPrice {
+		return errors.NewAppError("INVALID_INPUT", fmt.Sprintf("price must be at most %.2f", uc.cfg.MaxPrice), nil)
+	}
+	return nil
+}
+
+// checkDescription enforces the configured description length; zero disables
+// the limit.
+func (uc *productUsecase) checkDescription(description string) error {
+	limit := uc.cfg.MaxDescriptionLength
+	if limit > 0 && utf8.RuneCountInString(description) > limit {
+		return errors.NewAppError("VALIDATION", fmt.Sprintf("description must be at most %d characters", limit), nil)
+	}
+	return nil
+}
+
+// normalizeTitle trims and collapses whitespace in a title. It returns the
+// display form, which keeps the original casing, and the lower-cased form
+// used for uniqueness checks and lookups.
+func normalizeTitle(title string) (string, string) {
+	display := strings.Join(strings.Fields(title), " ")
+	return display, strings.ToLower(display)
 }
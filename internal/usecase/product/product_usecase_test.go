@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	errorsLib "errors"
+	"io"
+	"marketplace/internal/adapter/postgres/category"
+	"marketplace/internal/adapter/postgres/product"
+	"marketplace/internal/entity"
+	"marketplace/pkg/authctx"
+	"marketplace/pkg/config"
+	"marketplace/pkg/dto"
+	"marketplace/pkg/errors"
+	"marketplace/pkg/money"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+func notFound(what string) error {
+	return errors.NewAppError("NOT_FOUND", what+" not found", errors.ErrNotFound)
+}
+
+// fakeProducts keeps products in memory. Methods the tests do not reach
+// panic through the nil embedded interface.
+type fakeProducts struct {
+	product.ProductRepository
+	products map[string]*entity.Product
+}
+
+func (f *fakeProducts) Create(_ context.Context, p *entity.Product) error {
+	for _, existing := range f.products {
+		if existing.TitleKey == p.TitleKey {
+			return errors.NewAppError("DUPLICATE", "product already exists", nil)
+		}
+	}
+	stored := *p
+	f.products[p.ID] = &stored
+	return nil
+}
+
+func (f *fakeProducts) GetByID(_ context.Context, id string) (*entity.Product, error) {
+	p, ok := f.products[id]
+	if !ok {
+		return nil, notFound("product")
+	}
+	found := *p
+	return &found, nil
+}
+
+func (f *fakeProducts) GetByTitle(_ context.Context, title string) (*entity.Product, error) {
+	return f.find(func(p *entity.Product) bool { return p.TitleNormalized == title })
+}
+
+func (f *fakeProducts) GetByTitleAndSeller(_ context.Context, title, sellerID string) (*entity.Product, error) {
+	return f.find(func(p *entity.Product) bool { return p.TitleNormalized == title && p.SellerID == sellerID })
+}
+
+func (f *fakeProducts) GetByTitleAndCategory(_ context.Context, title, categoryID string) (*entity.Product, error) {
+	return f.find(func(p *entity.Product) bool { return p.TitleNormalized == title && p.CategoryID == categoryID })
+}
+
+func (f *fakeProducts) find(match func(*entity.Product) bool) (*entity.Product, error) {
+	for _, p := range f.products {
+		if match(p) {
+			found := *p
+			return &found, nil
+		}
+	}
+	return nil, notFound("product")
+}
+
+// List returns the products of a category, or nil when there are none, as a
+// scan loop over no rows would.
+func (f *fakeProducts) List(_ context.Context, categoryID string, _ product.ListFilter, _, _ int) ([]entity.Product, int64, error) {
+	var products []entity.Product
+	for _, p := range f.products {
+		if p.CategoryID == categoryID {
+			products = append(products, *p)
+		}
+	}
+	return products, int64(len(products)), nil
+}
+
+func (f *fakeProducts) Update(_ context.Context, p *entity.Product) error {
+	current, ok := f.products[p.ID]
+	if !ok {
+		return notFound("product")
+	}
+	if p.Version > 0 && p.Version != current.Version {
+		return errors.NewAppError("CONFLICT", "product was modified", errors.ErrConflict)
+	}
+	current.CategoryID = p.CategoryID
+	current.Title = p.Title
+	current.TitleNormalized = p.TitleNormalized
+	current.TitleKey = p.TitleKey
+	current.Description = p.Description
+	current.Price = p.Price
+	current.Stock = p.Stock
+	current.UpdatedAt = p.UpdatedAt
+	current.Version++
+	return nil
+}
+
+// fakeCategories serves GetByID and ListChildren from a map of categories.
+type fakeCategories struct {
+	category.CategoryRepository
+	categories map[string]*entity.Category
+}
+
+func (f *fakeCategories) GetByID(_ context.Context, id string) (*entity.Category, error) {
+	c, ok := f.categories[id]
+	if !ok {
+		return nil, notFound("category")
+	}
+	return c, nil
+}
+
+func (f *fakeCategories) ListChildren(_ context.Context, parentID string) ([]entity.Category, error) {
+	children := []entity.Category{}
+	for _, c := range f.categories {
+		if c.ParentID != nil && *c.ParentID == parentID {
+			children = append(children, *c)
+		}
+	}
+	return children, nil
+}
+
+type testEnv struct {
+	uc         *productUsecase
+	products   *fakeProducts
+	categories *fakeCategories
+}
+
+// newTestEnv builds a usecase over empty fakes with one category, "c1".
+func newTestEnv(cfg config.ProductConfig) *testEnv {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	products := &fakeProducts{products: map[string]*entity.Product{}}
+	categories := &fakeCategories{categories: map[string]*entity.Category{
+		"c1": {ID: "c1", Name: "Tools"},
+	}}
+	views := NewViewCounter(products, logger, 0)
+	uc := NewProductUsecase(products, categories, logger, validator.New(), cfg, "", money.NewFormatter(config.MoneyConfig{}), views)
+	return &testEnv{uc: uc, products: products, categories: categories}
+}
+
+func (e *testEnv) create(t *testing.T, title, sellerID string) *dto.ProductResponse {
+	t.Helper()
+	resp, err := e.uc.Create(context.Background(), &dto.CreateProductRequest{Title: title, Price: 10}, "c1", sellerID)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return resp
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !errorsLib.As(err, &appErr) || appErr.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in, display, normalized string
+	}{
+		{"  Widget  ", "Widget", "widget"},
+		{"Widget", "Widget", "widget"},
+		{"Big \t  Blue\nWidget", "Big Blue Widget", "big blue widget"},
+	}
+
+	for _, tt := range tests {
+		display, normalized := normalizeTitle(tt.in)
+		if display != tt.display || normalized != tt.normalized {
+			t.Errorf("normalizeTitle(%q) = %q, %q, want %q, %q", tt.in, display, normalized, tt.display, tt.normalized)
+		}
+	}
+}
+
+func TestCreateStoresTrimmedTitle(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+
+	resp := env.create(t, "  Widget  ", "seller-1")
+
+	stored := env.products.products[resp.ID]
+	if stored.Title != "Widget" || stored.TitleNormalized != "widget" {
+		t.Errorf("stored title %q, normalized %q, want %q, %q", stored.Title, stored.TitleNormalized, "Widget", "widget")
+	}
+}
+
+func TestCreateRejectsWhitespaceDuplicate(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+	env.create(t, "  Widget  ", "seller-1")
+
+	_, err := env.uc.Create(context.Background(), &dto.CreateProductRequest{Title: "widget", Price: 10}, "c1", "seller-2")
+	assertCode(t, err, "DUPLICATE")
+}
+
+func TestGetByTitleMatchesNormalizedTitle(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+	created := env.create(t, "Big Widget", "seller-1")
+
+	p, err := env.uc.GetByTitle(context.Background(), "  big   WIDGET ")
+	if err != nil {
+		t.Fatalf("GetByTitle: %v", err)
+	}
+	if p.ID != created.ID || p.Title != "Big Widget" {
+		t.Errorf("got %s %q, want %s %q", p.ID, p.Title, created.ID, "Big Widget")
+	}
+}
+
+func TestGetByTitleUnknownIsNotFound(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+
+	_, err := env.uc.GetByTitle(context.Background(), "No Such Widget")
+	assertCode(t, err, "NOT_FOUND")
+}
+
+func TestGetByTitleInactiveIsNotFound(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+	created := env.create(t, "Hidden Widget", "seller-1")
+	env.products.products[created.ID].IsActive = false
+
+	_, err := env.uc.GetByTitle(context.Background(), "Hidden Widget")
+	assertCode(t, err, "NOT_FOUND")
+}
+
+func TestUpdateIgnoresIDInBody(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+	target := env.create(t, "First Widget", "seller-1")
+	other := env.create(t, "Second Widget", "seller-1")
+
+	var req dto.UpdateProductRequest
+	body := `{"id": "` + other.ID + `", "category_id": "c1", "title": "Renamed Widget", "price": 20}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if _, err := env.uc.Update(context.Background(), &req, target.ID, "seller-1"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := env.products.products[target.ID].Title; got != "Renamed Widget" {
+		t.Errorf("path product title = %q, want %q", got, "Renamed Widget")
+	}
+	if got := env.products.products[other.ID].Title; got != "Second Widget" {
+		t.Errorf("body product title = %q, want it unchanged", got)
+	}
+}
+
+func TestCreateIgnoresSellerIDInBody(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+
+	var req dto.CreateProductRequest
+	if err := json.Unmarshal([]byte(`{"seller_id": "forged", "title": "Widget", "price": 10}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	resp, err := env.uc.Create(context.Background(), &req, "c1", "seller-1")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got := env.products.products[resp.ID].SellerID; got != "seller-1" {
+		t.Errorf("stored seller = %q, want the caller %q", got, "seller-1")
+	}
+}
+
+func TestListEmptyIsJSONArray(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+
+	page, err := env.uc.List(context.Background(), "c1", dto.ProductListFilter{}, "", 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	body, err := json.Marshal(page.Items)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != "[]" {
+		t.Errorf("items = %s, want []", body)
+	}
+}
+
+func TestCreateSellerOnlyRejectsCustomer(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{SellerOnlyCreate: true})
+	ctx := authctx.WithUser(context.Background(), authctx.User{ID: "customer-1", Type: "customer"})
+
+	_, err := env.uc.Create(ctx, &dto.CreateProductRequest{Title: "Widget", Price: 10}, "c1", "customer-1")
+	assertCode(t, err, "FORBIDDEN")
+	if len(env.products.products) != 0 {
+		t.Errorf("stored %d products, want none", len(env.products.products))
+	}
+
+	ctx = authctx.WithUser(context.Background(), authctx.User{ID: "seller-1", Type: "seller"})
+	if _, err := env.uc.Create(ctx, &dto.CreateProductRequest{Title: "Widget", Price: 10}, "c1", "seller-1"); err != nil {
+		t.Errorf("seller: Create: %v", err)
+	}
+}
+
+func TestCreateChecksCategory(t *testing.T) {
+	parent := "c1"
+	create := func(env *testEnv, categoryID string) error {
+		_, err := env.uc.Create(context.Background(), &dto.CreateProductRequest{Title: "Widget " + categoryID, Price: 10}, categoryID, "seller-1")
+		return err
+	}
+
+	env := newTestEnv(config.ProductConfig{LeafCategoriesOnly: true})
+	env.categories.categories["c2"] = &entity.Category{ID: "c2", Name: "Hammers", ParentID: &parent}
+
+	assertCode(t, create(env, "c1"), "INVALID_INPUT")
+	assertCode(t, create(env, "missing"), "NOT_FOUND")
+	if err := create(env, "c2"); err != nil {
+		t.Errorf("leaf category: %v", err)
+	}
+
+	env = newTestEnv(config.ProductConfig{})
+	env.categories.categories["c2"] = &entity.Category{ID: "c2", Name: "Hammers", ParentID: &parent}
+	if err := create(env, "c1"); err != nil {
+		t.Errorf("parent category without LeafCategoriesOnly: %v", err)
+	}
+}
+
+func TestGetByIDUnknownIsNotFound(t *testing.T) {
+	env := newTestEnv(config.ProductConfig{})
+
+	_, err := env.uc.GetByID(context.Background(), "missing")
+	assertCode(t, err, "NOT_FOUND")
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"marketplace/internal/adapter/postgres/product"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ViewCounter buffers product views in memory and writes them in batches,
+// so a page view costs a map update instead of a database write.
+type ViewCounter struct {
+	repo     product.ProductRepository
+	logger   *logrus.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[string]int64
+}
+
+// defaultViewFlushInterval replaces a non-positive flush interval.
+const defaultViewFlushInterval = 10 * time.Second
+
+func NewViewCounter(repo product.ProductRepository, logger *logrus.Logger, interval time.Duration) *ViewCounter {
+	if interval <= 0 {
+		interval = defaultViewFlushInterval
+	}
+	return &ViewCounter{
+		repo:     repo,
+		logger:   logger,
+		interval: interval,
+		pending:  make(map[string]int64),
+	}
+}
+
+// Record counts one view of the product.
+func (v *ViewCounter) Record(productID string) {
+	v.mu.Lock()
+	v.pending[productID]++
+	v.mu.Unlock()
+}
+
+// Run flushes the buffer every interval until ctx is done, then flushes
+// once more so views recorded before shutdown are kept.
+func (v *ViewCounter) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			v.Flush(ctx)
+		case <-ctx.Done():
+			// ctx is already canceled; give the last write its own deadline.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			v.Flush(flushCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+// Flush writes the buffered views. On failure they are put back and retried
+// with the next flush.
+func (v *ViewCounter) Flush(ctx context.Context) {
+	v.mu.Lock()
+	batch := v.pending
+	v.pending = make(map[string]int64, len(batch))
+	v.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := v.repo.IncrementViews(ctx, batch); err != nil {
+		v.logger.WithFields(logrus.Fields{
+			"operation": "flush_views",
+			"products":  len(batch),
+			"error":     err,
+		}).Warn("Failed flush product views")
+
+		v.mu.Lock()
+		for id, n := range batch {
+			v.pending[id] += n
+		}
+		v.mu.Unlock()
+		return
+	}
+
+	v.logger.WithFields(logrus.Fields{
+		"operation": "flush_views",
+		"products":  len(batch),
+	}).Debug("Product views flushed")
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"marketplace/internal/adapter/postgres/product"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeViews records IncrementViews batches. The first failures calls fail
+// without writing.
+type fakeViews struct {
+	product.ProductRepository
+
+	mu       sync.Mutex
+	failures int
+	calls    int
+	written  []map[string]int64
+}
+
+func (f *fakeViews) IncrementViews(_ context.Context, views map[string]int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("connection reset")
+	}
+	f.written = append(f.written, views)
+	return nil
+}
+
+func newTestViewCounter(repo *fakeViews, interval time.Duration) *ViewCounter {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewViewCounter(repo, logger, interval)
+}
+
+func assertViews(t *testing.T, got, want map[string]int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("views = %v, want %v", got, want)
+	}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("views = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+func TestViewCounterFlushWritesRecordedViews(t *testing.T) {
+	repo := &fakeViews{}
+	views := newTestViewCounter(repo, 0)
+
+	views.Flush(context.Background())
+	if repo.calls != 0 {
+		t.Errorf("empty flush wrote %d batches", repo.calls)
+	}
+
+	views.Record("p1")
+	views.Record("p2")
+	views.Record("p1")
+	views.Flush(context.Background())
+	views.Flush(context.Background())
+
+	if len(repo.written) != 1 {
+		t.Fatalf("wrote %d batches, want 1", len(repo.written))
+	}
+	assertViews(t, repo.written[0], map[string]int64{"p1": 2, "p2": 1})
+}
+
+func TestViewCounterRequeuesFailedFlush(t *testing.T) {
+	repo := &fakeViews{failures: 1}
+	views := newTestViewCounter(repo, 0)
+
+	views.Record("p1")
+	views.Record("p2")
+	views.Flush(context.Background())
+	if len(repo.written) != 0 {
+		t.Fatalf("failed flush wrote %v", repo.written)
+	}
+
+	// Views recorded after the failure join the re-queued ones.
+	views.Record("p1")
+	views.Flush(context.Background())
+
+	if len(repo.written) != 1 {
+		t.Fatalf("wrote %d batches, want 1", len(repo.written))
+	}
+	assertViews(t, repo.written[0], map[string]int64{"p1": 2, "p2": 1})
+}
+
+func TestViewCounterRunFlushesOnStop(t *testing.T) {
+	repo := &fakeViews{}
+	// The interval never elapses, so only the final flush writes.
+	views := newTestViewCounter(repo, time.Hour)
+	views.Record("p1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		views.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if len(repo.written) != 1 {
+		t.Fatalf("wrote %d batches, want 1", len(repo.written))
+	}
+	assertViews(t, repo.written[0], map[string]int64{"p1": 1})
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_category_id;
+DROP INDEX IF EXISTS idx_products_title_normalized;
+DROP TABLE IF EXISTS products;
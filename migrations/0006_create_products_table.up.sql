@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS products (
+    id TEXT PRIMARY KEY,
+    seller_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    title_normalized TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    category_id TEXT NOT NULL REFERENCES categories(id),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_title_normalized ON products (title_normalized);
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_category_title;
+DROP INDEX IF EXISTS idx_products_seller_title;
+DROP INDEX IF EXISTS idx_products_title_normalized;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_title_normalized ON products (title_normalized);
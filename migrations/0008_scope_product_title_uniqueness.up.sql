@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_title_normalized;
+
+CREATE INDEX IF NOT EXISTS idx_products_title_normalized ON products (title_normalized);
+CREATE INDEX IF NOT EXISTS idx_products_seller_title ON products (seller_id, title_normalized);
+CREATE INDEX IF NOT EXISTS idx_products_category_title ON products (category_id, title_normalized);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_tokens_token;
+DROP INDEX IF EXISTS idx_tokens_user_id;
+
+DELETE FROM tokens t
+USING tokens newer
+WHERE t.user_id = newer.user_id
+  AND (t.created_at, t.id) < (newer.created_at, newer.id);
+
+ALTER TABLE tokens DROP CONSTRAINT IF EXISTS tokens_pkey;
+ALTER TABLE tokens ADD PRIMARY KEY (user_id);
+
+ALTER TABLE tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE tokens DROP COLUMN IF EXISTS id;
@@ -0,0 +1,11 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS id TEXT;
+UPDATE tokens SET id = gen_random_uuid()::text WHERE id IS NULL;
+ALTER TABLE tokens ALTER COLUMN id SET NOT NULL;
+
+ALTER TABLE tokens DROP CONSTRAINT IF EXISTS tokens_pkey;
+ALTER TABLE tokens ADD PRIMARY KEY (id);
+
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tokens_token ON tokens (token);
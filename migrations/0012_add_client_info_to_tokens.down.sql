@@ -0,0 +1,2 @@
+ALTER TABLE tokens DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE tokens DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,2 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS ip_address TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_featured;
+ALTER TABLE products DROP COLUMN IF EXISTS featured;
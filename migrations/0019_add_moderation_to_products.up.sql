@@ -0,0 +1,6 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS moderation_status TEXT NOT NULL DEFAULT 'approved'
+    CHECK (moderation_status IN ('approved', 'pending', 'rejected'));
+ALTER TABLE products ADD COLUMN IF NOT EXISTS moderation_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_products_moderation_pending ON products (created_at, id) WHERE moderation_status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_products_seller_moderation ON products (seller_id, moderation_status);
@@ -0,0 +1,5 @@
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS address_country,
+    DROP COLUMN IF EXISTS address_postal_code,
+    DROP COLUMN IF EXISTS address_city,
+    DROP COLUMN IF EXISTS address_line1;
@@ -0,0 +1,8 @@
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS address_line1 TEXT,
+    ADD COLUMN IF NOT EXISTS address_city TEXT,
+    ADD COLUMN IF NOT EXISTS address_postal_code TEXT,
+    ADD COLUMN IF NOT EXISTS address_country CHAR(2);
+
+-- The old free-text address is the best guess for the first line.
+UPDATE customers SET address_line1 = address WHERE address IS NOT NULL AND address <> '';
@@ -0,0 +1,3 @@
+-- Not unique: uniqueness is enforced by the application when
+-- auth.unique_phone is on, so it can be switched per environment.
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers (phone) WHERE phone IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_product_images_primary;
+ALTER TABLE product_images DROP COLUMN IF EXISTS is_primary;
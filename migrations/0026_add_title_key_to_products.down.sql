@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_title_key;
+ALTER TABLE products DROP COLUMN IF EXISTS title_key;
@@ -0,0 +1,10 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS title_key TEXT;
+
+-- Existing rows get the narrowest key every title scope agrees on; rows
+-- written from now on carry the key of the configured scope.
+UPDATE products SET title_key = seller_id || '/' || category_id || '/' || title_normalized WHERE title_key IS NULL;
+
+ALTER TABLE products ALTER COLUMN title_key SET NOT NULL;
+
+-- Enforces product.title_unique_scope. Soft deleted products free their title.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_title_key ON products (title_key) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone_key TEXT;
+
+-- Enforces auth.unique_phone. The application copies the phone here only
+-- while the setting is on and clears it when the user is soft deleted, so
+-- phones written with the setting off are not checked. Existing phones are
+-- keyed on their next profile update.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_phone_key ON customers (phone_key);
@@ -0,0 +1,25 @@
+package authctx
+
+import "context"
+
+// User is the authenticated caller as established by the auth middleware.
+type User struct {
+	ID   string
+	Type string
+	// ImpersonatorID holds the admin id when the access token was issued
+	// through impersonation.
+	ImpersonatorID string
+}
+
+type ctxKey struct{}
+
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, user)
+}
+
+// FromContext returns the caller stored in ctx, or an empty User for
+// unauthenticated requests.
+func FromContext(ctx context.Context) User {
+	user, _ := ctx.Value(ctxKey{}).(User)
+	return user
+}
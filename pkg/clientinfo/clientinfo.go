@@ -0,0 +1,27 @@
+package clientinfo
+
+import "context"
+
+// maxUserAgentLen caps the stored User-Agent; real browsers stay well below it.
+const maxUserAgentLen = 512
+
+// Info describes the client that issued a request.
+type Info struct {
+	UserAgent string
+	IP        string
+}
+
+type ctxKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	if len(info.UserAgent) > maxUserAgentLen {
+		info.UserAgent = info.UserAgent[:maxUserAgentLen]
+	}
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+// FromContext returns the client info stored in ctx, or an empty Info.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(ctxKey{}).(Info)
+	return info
+}
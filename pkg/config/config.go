@@ -2,15 +2,30 @@ package config
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Logger LoggerConfig `mapstructure:"logger"`
-	Server ServerConfig `mapstructure:"server"`
-	DB     DBConfig     `mapstructure:"db"`
-	JWT    JWTConfig    `mapstructure:"jwt"`
+	Logger  LoggerConfig  `mapstructure:"logger"`
+	Server  ServerConfig  `mapstructure:"server"`
+	DB      DBConfig      `mapstructure:"db"`
+	JWT     JWTConfig     `mapstructure:"jwt"`
+	Product ProductConfig `mapstructure:"product"`
+	Bcrypt  BcryptConfig  `mapstructure:"bcrypt"`
+	Images  ImagesConfig  `mapstructure:"images"`
+	Auth    AuthConfig    `mapstructure:"auth"`
+	Admin   AdminConfig   `mapstructure:"admin"`
+	CORS    CORSConfig    `mapstructure:"cors"`
+	Debug   DebugConfig   `mapstructure:"debug"`
+	// DeletePolicy selects soft or hard deletion per entity.
+	DeletePolicy DeletePolicyConfig `mapstructure:"delete_policy"`
+	Money        MoneyConfig        `mapstructure:"money"`
+	// Features switches endpoints on per environment.
+	Features FeaturesConfig `mapstructure:"features"`
 }
 
 type LoggerConfig struct {
@@ -18,8 +33,44 @@ type LoggerConfig struct {
 }
 
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port string `mapstructure:"port"`
+	Host              string        `mapstructure:"host"`
+	Port              string        `mapstructure:"port"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	// MaxHeaderBytes bounds the size of request headers, including the
+	// request line.
+	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+	// JSONCase is the default key style of responses, "snake" or "camel".
+	// Clients can override it with "Accept: application/json; case=camel".
+	JSONCase string `mapstructure:"json_case"`
+	// StrictJSON rejects request bodies with fields the endpoint does not
+	// accept instead of ignoring them.
+	StrictJSON bool `mapstructure:"strict_json"`
+	// MaxOffset is the largest offset list endpoints accept. Zero disables
+	// the cap.
+	MaxOffset int `mapstructure:"max_offset"`
+	// HandlerTimeout bounds the total time spent on one request, across all
+	// of its queries. Zero disables it.
+	HandlerTimeout time.Duration `mapstructure:"handler_timeout"`
+}
+
+// ValidateTimeouts checks that the handler timeout leaves room for a full
+// database acquire and ends before the write timeout, so the 503 can still
+// be sent.
+func (c Config) ValidateTimeouts() error {
+	timeout := c.Server.HandlerTimeout
+	if timeout <= 0 {
+		return nil
+	}
+	if timeout < c.DB.AcquireTimeout {
+		return fmt.Errorf("server: handler_timeout %s is shorter than db.acquire_timeout %s", timeout, c.DB.AcquireTimeout)
+	}
+	if c.Server.WriteTimeout > 0 && timeout >= c.Server.WriteTimeout {
+		return fmt.Errorf("server: handler_timeout %s must be shorter than write_timeout %s", timeout, c.Server.WriteTimeout)
+	}
+	return nil
 }
 
 type DBConfig struct {
@@ -29,16 +80,232 @@ type DBConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
 	SSLMode  string `mapstructure:"sslmode"`
+
+	MaxConns       int32         `mapstructure:"max_conns"`
+	AcquireTimeout time.Duration `mapstructure:"acquire_timeout"`
+
+	MigrationsPath string `mapstructure:"migrations_path"`
 }
 
 type JWTConfig struct {
 	SecretKey string `mapstructure:"secret_key"`
-	ExpiresIn int    `mapstructure:"expires_in"`
+	// ExpiresIn is the access token lifetime.
+	ExpiresIn time.Duration `mapstructure:"expires_in"`
+	// RefreshExpiresIn is the refresh token lifetime.
+	RefreshExpiresIn time.Duration `mapstructure:"refresh_expires_in"`
+	MaxSessions      int           `mapstructure:"max_sessions"`
+	// Leeway is the clock skew tolerated when checking exp, iat and nbf.
+	Leeway time.Duration `mapstructure:"leeway"`
+	// RequireTokenType rejects tokens without a typ claim. Disable it only
+	// while tokens issued before the claim existed are still in circulation.
+	RequireTokenType bool `mapstructure:"require_token_type"`
+}
+
+// minTokenLifetime is the shortest token lifetime Validate accepts. It
+// mostly catches lifetimes written without a unit: viper decodes a bare 24
+// as 24ns, not 24 hours.
+const minTokenLifetime = time.Minute
+
+// Validate requires token lifetimes of at least a minute.
+func (c JWTConfig) Validate() error {
+	if c.ExpiresIn < minTokenLifetime {
+		return fmt.Errorf("jwt: expires_in must be at least %s, got %s; give a unit, e.g. \"15m\"", minTokenLifetime, c.ExpiresIn)
+	}
+	if c.RefreshExpiresIn < minTokenLifetime {
+		return fmt.Errorf("jwt: refresh_expires_in must be at least %s, got %s; give a unit, e.g. \"720h\"", minTokenLifetime, c.RefreshExpiresIn)
+	}
+	return nil
+}
+
+type BcryptConfig struct {
+	Cost int `mapstructure:"cost"`
+	// RehashCost is the minimum cost accepted at login; weaker hashes are
+	// upgraded to Cost. Zero disables rehashing.
+	RehashCost int `mapstructure:"rehash_cost"`
+}
+
+type ProductConfig struct {
+	// TitleUniqueScope is one of "global", "seller" or "category". The
+	// database enforces it through products.title_key, which is computed on
+	// write, so changing the scope only affects products written afterwards.
+	TitleUniqueScope string `mapstructure:"title_unique_scope"`
+	// MinPrice and MaxPrice bound product prices. Zero disables a bound.
+	MinPrice float64 `mapstructure:"min_price"`
+	MaxPrice float64 `mapstructure:"max_price"`
+	// MaxDescriptionLength caps descriptions in characters. The column is
+	// TEXT, so this is a product rule rather than a storage limit.
+	MaxDescriptionLength int `mapstructure:"max_description_length"`
+	// InStockOnly hides out-of-stock products from listings unless the
+	// client passes inStockOnly=false. Sellers always see every product.
+	InStockOnly bool `mapstructure:"in_stock_only"`
+	// SellerOnlyCreate makes the usecase itself reject product creation by
+	// callers that are not sellers, on top of the route role check.
+	SellerOnlyCreate bool `mapstructure:"seller_only_create"`
+	// FeaturedBy is who may feature products: "admin", or "seller" to also
+	// let sellers feature their own products.
+	FeaturedBy string `mapstructure:"featured_by"`
+	// LeafCategoriesOnly rejects products in categories that have
+	// subcategories.
+	LeafCategoriesOnly bool `mapstructure:"leaf_categories_only"`
+	// RequireModeration creates products as pending and hidden until an
+	// admin approves them.
+	RequireModeration bool `mapstructure:"require_moderation"`
+	// MaxImportRows caps the rows of one catalog import request.
+	MaxImportRows int `mapstructure:"max_import_rows"`
+	// ViewFlushInterval is how often buffered product views are written.
+	ViewFlushInterval time.Duration `mapstructure:"view_flush_interval"`
+}
+
+type ImagesConfig struct {
+	// AllowedHosts lists hostnames image URLs may point to. An entry of the
+	// form "*.example.com" matches any subdomain of example.com. An empty
+	// list allows any host.
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+}
+
+type AuthConfig struct {
+	// RequireEmailVerification makes Register return the created user
+	// without tokens; the client has to verify the email and log in.
+	RequireEmailVerification bool `mapstructure:"require_email_verification"`
+	// InferUserType lets Login look the user_type up from the users table
+	// when the client omits it.
+	InferUserType bool `mapstructure:"infer_user_type"`
+	// PasswordHistory is how many recent passwords, the current one
+	// included, a new password must differ from. Zero disables the check.
+	PasswordHistory int `mapstructure:"password_history"`
+	// MaxIdentifierLength caps the email and username accepted by Register
+	// and Login before any validation or lookup runs. Zero disables the cap.
+	MaxIdentifierLength int `mapstructure:"max_identifier_length"`
+	// DegradeOnTokenStoreError lets Login succeed with only an access token
+	// when the refresh token cannot be stored. Off, the login fails.
+	DegradeOnTokenStoreError bool `mapstructure:"degrade_on_token_store_error"`
+	// UniquePhone rejects a customer phone already used by another active
+	// customer. The database enforces it through customers.phone_key, which
+	// only phones written while it is on carry.
+	UniquePhone bool `mapstructure:"unique_phone"`
+	// VerificationTTL is how long an email verification token stays valid.
+	VerificationTTL time.Duration `mapstructure:"verification_ttl"`
+	// BlockUnverifiedLogin rejects logins of customers and sellers that
+	// have not verified their email.
+	BlockUnverifiedLogin bool `mapstructure:"block_unverified_login"`
+}
+
+type AdminConfig struct {
+	// ImpersonationTTL is the lifetime of impersonation access tokens.
+	ImpersonationTTL time.Duration `mapstructure:"impersonation_ttl"`
+	// ImpersonationsPerHour caps impersonation tokens per admin. Zero
+	// disables the cap.
+	ImpersonationsPerHour int `mapstructure:"impersonations_per_hour"`
+}
+
+// MoneyConfig sets how prices are rendered in price_display fields.
+// Products carry no currency of their own, so Currency applies to all.
+type MoneyConfig struct {
+	// Currency is an ISO 4217 code such as "USD".
+	Currency string `mapstructure:"currency"`
+	// Locale is a BCP 47 tag such as "en-US"; only the language is used.
+	Locale string `mapstructure:"locale"`
+}
+
+type DebugConfig struct {
+	// EnableTestRoute registers POST /test for checking request parsing. Keep
+	// it off in production.
+	EnableTestRoute bool `mapstructure:"enable_test_route"`
+	// ErrorDetails adds the full error chain to error responses under
+	// "debug". It leaks internals, so never turn it on in production.
+	ErrorDetails bool `mapstructure:"error_details"`
+	// LogMailTokens makes the log mailer include verification tokens, so
+	// emails can be confirmed locally. Keep it off outside development.
+	LogMailTokens bool `mapstructure:"log_mail_tokens"`
+}
+
+const (
+	// DeleteSoft keeps the row and hides it; DeleteHard removes it.
+	DeleteSoft = "soft"
+	DeleteHard = "hard"
+)
+
+// DeletePolicyConfig holds DeleteSoft or DeleteHard for each entity. Soft
+// deleted products are deactivated, users and categories get deleted_at.
+type DeletePolicyConfig struct {
+	Products   string `mapstructure:"products" json:"products"`
+	Categories string `mapstructure:"categories" json:"categories"`
+	Users      string `mapstructure:"users" json:"users"`
+}
+
+func (c DeletePolicyConfig) Validate() error {
+	for _, p := range []struct{ entity, policy string }{
+		{"products", c.Products},
+		{"categories", c.Categories},
+		{"users", c.Users},
+	} {
+		if p.policy != DeleteSoft && p.policy != DeleteHard {
+			return fmt.Errorf("delete_policy: %s must be %q or %q, got %q", p.entity, DeleteSoft, DeleteHard, p.policy)
+		}
+	}
+	return nil
+}
+
+// Feature names used in FeaturesConfig.
+const (
+	FeatureSearch = "search"
+)
+
+// FeaturesConfig maps a feature name to whether its routes are served.
+// Features that are not listed are disabled.
+type FeaturesConfig map[string]bool
+
+// Enabled reports whether the feature is switched on. Names are matched
+// case-insensitively, as viper lowercases map keys.
+func (f FeaturesConfig) Enabled(name string) bool {
+	return f[strings.ToLower(name)]
+}
+
+// EnabledNames returns the enabled features in sorted order.
+func (f FeaturesConfig) EnabledNames() []string {
+	var names []string
+	for name, on := range f {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CORSAnyOrigin in AllowedOrigins allows every origin.
+const CORSAnyOrigin = "*"
+
+type CORSConfig struct {
+	// AllowedOrigins lists the origins browsers may call the API from. An
+	// empty list disables CORS.
+	AllowedOrigins []string      `mapstructure:"allowed_origins"`
+	AllowedMethods []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders []string      `mapstructure:"exposed_headers"`
+	MaxAge         time.Duration `mapstructure:"max_age"`
+	// AllowCredentials lets browsers send cookies and Authorization headers.
+	// It cannot be combined with the "*" origin.
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+}
+
+// Validate rejects CORS settings that browsers would refuse.
+func (c CORSConfig) Validate() error {
+	if !c.AllowCredentials {
+		return nil
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == CORSAnyOrigin {
+			return fmt.Errorf("cors: allow_credentials requires explicit allowed_origins, not %q", CORSAnyOrigin)
+		}
+	}
+	return nil
 }
 
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
+	SetDefaults()
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("error reading config file: %w", err)
@@ -51,3 +318,47 @@ func Load(configPath string) (*Config, error) {
 
 	return &config, nil
 }
+
+// SetDefaults registers fallback values for optional settings on the global
+// viper instance.
+func SetDefaults() {
+	viper.SetDefault("server.read_timeout", "10s")
+	viper.SetDefault("server.read_header_timeout", "5s")
+	viper.SetDefault("server.write_timeout", "15s")
+	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.max_header_bytes", 64<<10)
+	viper.SetDefault("server.json_case", "snake")
+	viper.SetDefault("server.strict_json", false)
+	viper.SetDefault("server.max_offset", 10000)
+	viper.SetDefault("server.handler_timeout", "10s")
+	viper.SetDefault("jwt.expires_in", "15m")
+	viper.SetDefault("jwt.refresh_expires_in", "720h")
+	viper.SetDefault("jwt.leeway", "30s")
+	viper.SetDefault("jwt.require_token_type", true)
+	viper.SetDefault("product.seller_only_create", true)
+	viper.SetDefault("product.max_description_length", 999)
+	viper.SetDefault("product.featured_by", "admin")
+	viper.SetDefault("product.leaf_categories_only", false)
+	viper.SetDefault("product.require_moderation", false)
+	viper.SetDefault("auth.infer_user_type", true)
+	viper.SetDefault("auth.password_history", 5)
+	viper.SetDefault("auth.max_identifier_length", 254)
+	viper.SetDefault("db.migrations_path", "migrations")
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "X-API-Token"})
+	viper.SetDefault("cors.max_age", "10m")
+	viper.SetDefault("debug.enable_test_route", false)
+	viper.SetDefault("debug.error_details", false)
+	viper.SetDefault("money.currency", "USD")
+	viper.SetDefault("money.locale", "en-US")
+	viper.SetDefault("delete_policy.products", DeleteSoft)
+	viper.SetDefault("delete_policy.categories", DeleteHard)
+	viper.SetDefault("delete_policy.users", DeleteSoft)
+	viper.SetDefault("product.max_import_rows", 1000)
+	viper.SetDefault("product.view_flush_interval", "10s")
+	viper.SetDefault("auth.degrade_on_token_store_error", false)
+	viper.SetDefault("auth.unique_phone", false)
+	viper.SetDefault("auth.verification_ttl", "24h")
+	viper.SetDefault("auth.block_unverified_login", false)
+	viper.SetDefault("features.search", true)
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJWTConfigValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg     JWTConfig
+		wantErr bool
+	}{
+		"units":             {cfg: JWTConfig{ExpiresIn: 15 * time.Minute, RefreshExpiresIn: 720 * time.Hour}},
+		"bare access hours": {cfg: JWTConfig{ExpiresIn: 24, RefreshExpiresIn: 720 * time.Hour}, wantErr: true},
+		"bare refresh":      {cfg: JWTConfig{ExpiresIn: 15 * time.Minute, RefreshExpiresIn: 720}, wantErr: true},
+		"sub-minute":        {cfg: JWTConfig{ExpiresIn: 30 * time.Second, RefreshExpiresIn: time.Hour}, wantErr: true},
+		"missing":           {cfg: JWTConfig{}, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
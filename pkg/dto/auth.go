@@ -1,28 +1,52 @@
 package dto
 
+import "time"
+
 type RegisterRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email    string `json:"email" validate:"required,max=254,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 	UserType string `json:"user_type" validate:"required,oneof=customer seller"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"omitempty,email"`
-	Username string `json:"username" validate:"omitempty,min=3"`
-	Password string `json:"password" validate:"required"`
-	UserType string `json:"user_type" validate:"required,oneof=customer seller"`
+	Email    string `json:"email" validate:"omitempty,max=254,email"`
+	Username string `json:"username" validate:"omitempty,min=3,max=50"`
+	Password string `json:"password" validate:"required,max=72"`
+	// UserType may be omitted; it is then looked up by email or username.
+	UserType string `json:"user_type" validate:"omitempty,oneof=customer seller admin"`
 }
 
+// AuthStatusVerificationRequired is reported by Register when tokens are
+// withheld until the email is verified.
+const AuthStatusVerificationRequired = "verification_required"
+
 type AuthResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	User         *UserInfo `json:"user,omitempty"`
+	// Warning explains a degraded response, such as a missing refresh token.
+	Warning string `json:"warning,omitempty"`
+	// AccessTokenExpiresIn is the access token lifetime in seconds, so
+	// clients can refresh shortly before it runs out.
+	AccessTokenExpiresIn int64 `json:"access_token_expires_in,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// IsEmpty reports whether the request changes nothing. OldPassword and
+// RefreshToken only authorize the change.
+func (r UpdateAuthRequest) IsEmpty() bool {
+	return r.Email == "" && r.Username == "" && r.NewPassword == ""
+}
+
 type UserInfo struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
@@ -40,10 +64,10 @@ type TokenClaims struct {
 }
 
 type UpdateAuthRequest struct {
-	Email        string `json:"email" validate:"omitempty,email"`
+	Email        string `json:"email" validate:"omitempty,max=254,email"`
 	Username     string `json:"username" validate:"omitempty,min=3,max=50"`
-	OldPassword  string `json:"old_password" validate:"required_with=NewPassword"`
-	NewPassword  string `json:"new_password" validate:"omitempty,min=8"`
+	OldPassword  string `json:"old_password" validate:"required_with=NewPassword,max=72"`
+	NewPassword  string `json:"new_password" validate:"omitempty,min=8,max=72"`
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
@@ -53,6 +77,27 @@ type CustomerProfileRequest struct {
 	LastName  string `json:"last_name" validate:"omitempty,min=2,max=50"`
 	Address   string `json:"address" validate:"omitempty"`
 	DateBirth string `json:"date_birth" validate:"omitempty,datetime=2006-01-02"` // ISO формат
+	// ShippingAddress replaces the structured address when present.
+	ShippingAddress *ShippingAddress `json:"shipping_address" validate:"omitempty"`
+}
+
+// ShippingAddress is a structured postal address. Country is an upper-case
+// ISO 3166-1 alpha-2 code.
+type ShippingAddress struct {
+	Line1      string `json:"line1" validate:"required,max=200"`
+	City       string `json:"city" validate:"required,max=100"`
+	PostalCode string `json:"postal_code" validate:"required,max=20"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+// String formats the address on one line, as stored in the free-text
+// address field.
+func (a ShippingAddress) String() string {
+	return a.Line1 + ", " + a.City + " " + a.PostalCode + ", " + a.Country
+}
+
+func (r CustomerProfileRequest) IsEmpty() bool {
+	return r == CustomerProfileRequest{}
 }
 
 type SellerProfileRequest struct {
@@ -60,6 +105,10 @@ type SellerProfileRequest struct {
 	Rating      float64 `json:"rating" validate:"omitempty,min=0,max=5"`
 }
 
+func (r SellerProfileRequest) IsEmpty() bool {
+	return r == SellerProfileRequest{}
+}
+
 type CustomerProfileResponse struct {
 	ID        string `json:"id"`
 	Username  string `json:"username"`
@@ -70,6 +119,8 @@ type CustomerProfileResponse struct {
 	Address   string `json:"address"`
 	DateBirth string `json:"date_birth"`
 	UserType  string `json:"user_type"`
+	// ShippingAddress is omitted until the customer sets one.
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
 }
 
 type SellerProfileResponse struct {
@@ -80,3 +131,59 @@ type SellerProfileResponse struct {
 	Rating      float64 `json:"rating"`
 	UserType    string  `json:"user_type"`
 }
+
+type APITokenResponse struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	UserID      string    `json:"user_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AuditFilter narrows the admin audit log listing. Empty fields match all.
+type AuditFilter struct {
+	UserID string `form:"user_id"`
+	Event  string `form:"event"`
+}
+
+type AuditEventResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Event     string    `json:"event"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type SessionResponse struct {
+	ID         string     `json:"id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// Session statuses reported by SessionStatusResponse.
+const (
+	SessionStatusValid   = "valid"
+	SessionStatusRevoked = "revoked"
+	SessionStatusExpired = "expired"
+)
+
+// SessionStatusResponse describes a stored refresh token without exposing
+// the token itself.
+type SessionStatusResponse struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "database/sql"
+
+// DateLayout is the wire format for calendar dates such as date_birth.
+const DateLayout = "2006-01-02"
+
+// NullString returns the string value, or "" when it is NULL.
+func NullString(ns sql.NullString) string {
+	if !ns.Valid {
+		return ""
+	}
+	return ns.String
+}
+
+// NullDate formats the time as DateLayout, or returns "" when it is NULL.
+func NullDate(nt sql.NullTime) string {
+	if !nt.Valid {
+		return ""
+	}
+	return nt.Time.Format(DateLayout)
+}
+
+// NullFloat64 returns the float value, or 0 when it is NULL.
+func NullFloat64(nf sql.NullFloat64) float64 {
+	if !nf.Valid {
+		return 0
+	}
+	return nf.Float64
+}
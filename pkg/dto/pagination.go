@@ -0,0 +1,31 @@
+package dto
+
+// PageMeta is the "meta" object of paginated list responses.
+type PageMeta struct {
+	// Total is the number of matching items across all pages. It is omitted
+	// by endpoints that do not count.
+	Total  *int64 `json:"total,omitempty"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	// NextCursor is set by cursor-paginated endpoints when another page exists.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func NewPageMeta(limit, offset int) PageMeta {
+	return PageMeta{Limit: limit, Offset: offset}
+}
+
+// PaginatedResponse is one page of items together with the total number of
+// matching items.
+type PaginatedResponse[T any] struct {
+	Items  []T   `json:"items"`
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// Meta returns the PageMeta describing the page.
+func (p *PaginatedResponse[T]) Meta() PageMeta {
+	total := p.Total
+	return PageMeta{Total: &total, Limit: p.Limit, Offset: p.Offset}
+}
@@ -1,34 +1,235 @@
 package dto
 
+import "time"
+
+// CreateProductRequest carries no seller: the product always belongs to the
+// authenticated caller, and a seller_id in the body is not decoded.
 type CreateProductRequest struct {
-	SellerID    string  `json:"seller_id" validate:"required"`
-	CategoryID  string  `json:"category_id" validate:"required"`
+	// CategoryID is optional; the category comes from the URL.
+	CategoryID  string  `json:"category_id" validate:"omitempty"`
 	Title       string  `json:"title" validate:"required,min=5,max=20"`
-	Description string  `json:"description" validate:"omitempty,max=999"`
+	Description string  `json:"description"`
 	Price       float64 `json:"price" validate:"required,min=0"`
+	Stock       int     `json:"stock" validate:"omitempty,min=0"`
 }
 
 type ProductResponse struct {
+	ID         string  `json:"id"`
 	SellerID   string  `json:"seller_id" validate:"required"`
 	CategoryID string  `json:"category_id" validate:"required"`
 	Title      string  `json:"title" validate:"required,min=5,max=20"`
 	Price      float64 `json:"price" validate:"required,min=0"`
+	// PriceDisplay is Price formatted for the configured currency and locale.
+	PriceDisplay     string `json:"price_display"`
+	Stock            int    `json:"stock"`
+	Version          int    `json:"version"`
+	Featured         bool   `json:"featured"`
+	ModerationStatus string `json:"moderation_status"`
+	// ModerationReason is only set for rejected products.
+	ModerationReason string     `json:"moderation_reason,omitempty"`
+	ViewCount        int64      `json:"view_count"`
+	PrimaryImageURL  string     `json:"primary_image_url,omitempty"`
+	Images           []ImageDTO `json:"images,omitempty"`
+}
+
+// ProductExportRow is one product in a seller catalog export.
+type ProductExportRow struct {
+	ID               string    `json:"id"`
+	CategoryID       string    `json:"category_id"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	Price            float64   `json:"price"`
+	Stock            int       `json:"stock"`
+	IsActive         bool      `json:"is_active"`
+	ModerationStatus string    `json:"moderation_status"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	// ImageURLs is only filled when the export asks for images.
+	ImageURLs []string `json:"image_urls,omitempty"`
+}
+
+// ImportProductRow is one parsed row of a catalog import. Row is the 1-based
+// data row number used in error reports.
+type ImportProductRow struct {
+	Row     int
+	Product CreateProductRequest
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportProductsResponse reports a catalog import. Rows listed in Errors
+// were skipped; every other row was imported.
+type ImportProductsResponse struct {
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+type RejectProductRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// ProductDetailResponse is the single-product payload; unlike list items it
+// always carries the product images.
+type ProductDetailResponse struct {
+	ID           string     `json:"id"`
+	SellerID     string     `json:"seller_id"`
+	CategoryID   string     `json:"category_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Price        float64    `json:"price"`
+	PriceDisplay string     `json:"price_display"`
+	Stock        int        `json:"stock"`
+	IsActive     bool       `json:"is_active"`
+	Version      int        `json:"version"`
+	Featured     bool       `json:"featured"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Images       []ImageDTO `json:"images"`
+}
+
+// ProductCard is the compact product payload for list and grid views.
+type ProductCard struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	Price         float64 `json:"price"`
+	PriceDisplay  string  `json:"price_display"`
+	CategoryName  string  `json:"category_name"`
+	SellerCompany string  `json:"seller_company"`
 }
 
 type UpdateProductRequest struct {
-	ID          string  `json:"id" validate:"required"`
 	CategoryID  string  `json:"category_id" validate:"required"`
 	Title       string  `json:"title" validate:"required,min=5,max=20"`
-	Description string  `json:"description" validate:"omitempty,max=999"`
+	Description string  `json:"description"`
 	Price       float64 `json:"price" validate:"required,min=0"`
+	Stock       int     `json:"stock" validate:"omitempty,min=0"`
+	// Version is the last version the client has seen. When set, the update
+	// fails with 409 if the product changed in the meantime.
+	Version int `json:"version" validate:"omitempty,min=1"`
+}
+
+// ProductListFilter is the filter applied to a product listing. It is also
+// echoed back in the response meta.
+type ProductListFilter struct {
+	InStockOnly bool     `json:"in_stock_only"`
+	MinPrice    *float64 `json:"min_price,omitempty"`
+	MaxPrice    *float64 `json:"max_price,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// ProductSort is the sort query parameter of product listings.
+type ProductSort string
+
+const (
+	ProductSortNewest    ProductSort = "newest"
+	ProductSortPriceAsc  ProductSort = "price_asc"
+	ProductSortPriceDesc ProductSort = "price_desc"
+	ProductSortTitle     ProductSort = "title"
+)
+
+// ParseProductSort maps a sort query value to a ProductSort. An empty value
+// is ProductSortNewest; unknown values report false.
+func ParseProductSort(raw string) (ProductSort, bool) {
+	switch s := ProductSort(raw); s {
+	case "":
+		return ProductSortNewest, true
+	case ProductSortNewest, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortTitle:
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+type ProductListMeta struct {
+	PageMeta
+	Filter ProductListFilter `json:"filter"`
+	Sort   ProductSort       `json:"sort"`
+}
+
+type AvailabilityRequest struct {
+	ProductIDs []string `json:"product_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+type ProductAvailability struct {
+	ProductID string `json:"product_id"`
+	Found     bool   `json:"found"`
+	IsActive  bool   `json:"is_active"`
+	Stock     int    `json:"stock"`
+	Available bool   `json:"available"`
 }
 
 type CategoryDTO struct {
 	CategoryID string `json:"category_id" validate:"required"`
 	Name       string `json:"name" validate:"required,min=1,max=50"`
+	// ParentID nests the category under another one; nil makes it a root.
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,min=1"`
+}
+
+// PatchCategoryRequest updates only the fields that are present.
+type PatchCategoryRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=50"`
+}
+
+type CategoryNode struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Children []CategoryNode `json:"children"`
 }
 
 type ImageDTO struct {
+	// ID is set by the server; it is ignored on create.
+	ID        string `json:"id"`
 	ProductID string `json:"product_id" validate:"required"`
 	URL       string `json:"url" validate:"required"`
+	// IsPrimary is set by the server; use PUT /images/:imageID/primary.
+	IsPrimary bool `json:"is_primary"`
+}
+
+type DeleteImagesResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+type BulkDeleteImagesRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// BulkDeleteImagesResponse reports the ids that were not deleted because
+// they do not exist or belong to another seller.
+type BulkDeleteImagesResponse struct {
+	Deleted    int64    `json:"deleted"`
+	Skipped    int      `json:"skipped"`
+	SkippedIDs []string `json:"skipped_ids"`
+}
+
+type SetFeaturedRequest struct {
+	Featured *bool `json:"featured" validate:"required"`
+}
+
+type SetActiveRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+type SetActiveResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// SellerProductStats is the seller dashboard summary of product counts.
+type CategoryStatsResponse struct {
+	CategoryID   string  `json:"category_id"`
+	ProductCount int64   `json:"product_count"`
+	AvgPrice     float64 `json:"avg_price"`
+	MinPrice     float64 `json:"min_price"`
+	MaxPrice     float64 `json:"max_price"`
+	// ActiveRatio is the share of active products, 0 for an empty category.
+	ActiveRatio float64 `json:"active_ratio"`
+}
+
+type SellerProductStats struct {
+	Active   int64 `json:"active"`
+	Inactive int64 `json:"inactive"`
+	Total    int64 `json:"total"`
 }
@@ -1,13 +1,21 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
 
+const (
+	CodeRequestCanceled = "REQUEST_CANCELED"
+	CodeTimeout         = "TIMEOUT"
+	CodeUnavailable     = "SERVICE_UNAVAILABLE"
+)
+
 var (
 	ErrNotFound = errors.New("resource not found")
 	ErrInternal = errors.New("internal server error")
+	ErrConflict = errors.New("resource was modified concurrently")
 )
 
 type AppError struct {
@@ -39,3 +47,37 @@ func (a *AppError) Unwrap() error { return a.error }
 func (a *AppError) Code() string { return a.code }
 
 func (a *AppError) Message() string { return a.message }
+
+// FromContext classifies errors caused by a canceled or expired context as
+// REQUEST_CANCELED or TIMEOUT. It returns nil for any other error.
+func FromContext(err error) *AppError {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return NewAppError(CodeRequestCanceled, "request canceled", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewAppError(CodeTimeout, "request timed out", err)
+	}
+	return nil
+}
+
+// ContextError returns the REQUEST_CANCELED or TIMEOUT error anywhere in
+// err's chain, so wrapping by upper layers does not hide it.
+func ContextError(err error) *AppError {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if a, ok := err.(*AppError); ok && (a.code == CodeRequestCanceled || a.code == CodeTimeout) {
+			return a
+		}
+	}
+	return nil
+}
+
+// UnavailableError returns the SERVICE_UNAVAILABLE error anywhere in err's
+// chain, so a busy database is reported as such however it was wrapped.
+func UnavailableError(err error) *AppError {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if a, ok := err.(*AppError); ok && a.code == CodeUnavailable {
+			return a
+		}
+	}
+	return nil
+}
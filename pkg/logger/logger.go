@@ -20,6 +20,8 @@ type Logger interface {
 	Fatalf(format string, args ...interface{})
 }
 
+var _ Logger = (*LogrusLogger)(nil)
+
 type LogrusLogger struct {
 	logger *logrus.Logger
 }
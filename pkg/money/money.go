@@ -0,0 +1,130 @@
+// Package money formats prices for display.
+package money
+
+import (
+	"marketplace/pkg/config"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type localeFormat struct {
+	decimal string
+	group   string
+	// symbolAfter places the currency symbol after the amount, separated by
+	// a space.
+	symbolAfter bool
+}
+
+// defaultLocale is used for locales missing from locales.
+const defaultLocale = "en"
+
+// locales is keyed by language; the region part of a locale is ignored.
+var locales = map[string]localeFormat{
+	"en": {decimal: ".", group: ","},
+	"de": {decimal: ",", group: ".", symbolAfter: true},
+	"es": {decimal: ",", group: ".", symbolAfter: true},
+	"fr": {decimal: ",", group: " ", symbolAfter: true},
+	"ru": {decimal: ",", group: " ", symbolAfter: true},
+}
+
+var symbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"RUB": "₽",
+	"JPY": "¥",
+}
+
+// zeroDecimalCurrencies have no minor unit.
+var zeroDecimalCurrencies = map[string]bool{
+	"JPY": true,
+}
+
+// Formatter renders amounts in one currency and locale.
+type Formatter struct {
+	currency string
+	locale   string
+}
+
+func NewFormatter(cfg config.MoneyConfig) Formatter {
+	return Formatter{
+		currency: strings.ToUpper(cfg.Currency),
+		locale:   cfg.Locale,
+	}
+}
+
+// Format renders amount with the formatter's currency and locale.
+func (f Formatter) Format(amount float64) string {
+	return Format(amount, f.currency, f.locale)
+}
+
+// Format renders amount as a display string such as "$1,234.50" (en-US,
+// USD) or "1 234,50 ₽" (ru-RU, RUB). Unknown locales are formatted like
+// English and unknown currencies are shown by their code.
+func Format(amount float64, currency, locale string) string {
+	currency = strings.ToUpper(currency)
+	lf, ok := locales[language(locale)]
+	if !ok {
+		lf = locales[defaultLocale]
+	}
+
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+
+	number := formatNumber(math.Abs(amount), decimals, lf)
+	// Amounts that round to zero are shown without a sign.
+	negative := amount < 0 && strings.ContainsAny(number, "123456789")
+
+	symbol, ok := symbols[currency]
+	if !ok {
+		symbol = currency
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteString("-")
+	}
+	switch {
+	case lf.symbolAfter:
+		b.WriteString(number)
+		b.WriteString(" ")
+		b.WriteString(symbol)
+	case symbol == currency:
+		b.WriteString(symbol)
+		b.WriteString(" ")
+		b.WriteString(number)
+	default:
+		b.WriteString(symbol)
+		b.WriteString(number)
+	}
+
+	return b.String()
+}
+
+func formatNumber(amount float64, decimals int, lf localeFormat) string {
+	raw := strconv.FormatFloat(amount, 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(raw, ".")
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(lf.group)
+		}
+		b.WriteRune(digit)
+	}
+	if frac != "" {
+		b.WriteString(lf.decimal)
+		b.WriteString(frac)
+	}
+
+	return b.String()
+}
+
+// language returns the language part of a locale like "en-US" or "ru_RU".
+func language(locale string) string {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToLower(lang)
+}
@@ -0,0 +1,42 @@
+package money
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	// Group separators and the space before a trailing symbol are no-break
+	// spaces, so amounts do not wrap.
+	tests := []struct {
+		amount   float64
+		currency string
+		locale   string
+		want     string
+	}{
+		{1234.5, "USD", "en-US", "$1,234.50"},
+		{1234567.891, "USD", "en", "$1,234,567.89"},
+		{999.999, "USD", "en", "$1,000.00"},
+		{1234.5, "RUB", "ru-RU", "1 234,50 ₽"},
+		{1234.5, "EUR", "de_DE", "1.234,50 €"},
+		{1234.5, "EUR", "fr", "1 234,50 €"},
+		{0.5, "USD", "en", "$0.50"},
+		// JPY has no minor unit.
+		{1234.6, "JPY", "en", "¥1,235"},
+		{1234567, "jpy", "ru", "1 234 567 ¥"},
+		// Unknown locales fall back to English, unknown currencies show
+		// their code.
+		{1234.5, "USD", "xx-YY", "$1,234.50"},
+		{1234.5, "CHF", "en", "CHF 1,234.50"},
+		{1234.5, "CHF", "de", "1.234,50 CHF"},
+		// Negative amounts keep their sign unless they round to zero.
+		{-1234.5, "USD", "en", "-$1,234.50"},
+		{-0.005, "USD", "en", "-$0.01"},
+		{-0.001, "USD", "en", "$0.00"},
+		{-0.4, "JPY", "en", "¥0"},
+		{-1234.5, "RUB", "ru", "-1 234,50 ₽"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.amount, tt.currency, tt.locale); got != tt.want {
+			t.Errorf("Format(%v, %s, %s) = %q, want %q", tt.amount, tt.currency, tt.locale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	apperrors "marketplace/pkg/errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	codeAcquire = "ACQUIRE_CONN"
+)
+
+var _ Querier = poolQuerier{}
+
+// poolQuerier runs each call on a connection taken with Acquire, so every
+// query outside a transaction honours the acquire timeout and reports an
+// exhausted pool the same way.
+type poolQuerier struct {
+	pool *pgxpool.Pool
+}
+
+func (q poolQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	conn, err := q.acquire(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer conn.Release()
+
+	return conn.Exec(ctx, sql, args...)
+}
+
+// Query keeps the connection until the rows are closed or fully read.
+func (q poolQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	conn, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return &connRows{Rows: rows, release: releaseOnce(conn)}, nil
+}
+
+// QueryRow keeps the connection until the row is scanned.
+func (q poolQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	conn, err := q.acquire(ctx)
+	if err != nil {
+		return errRow{err: err}
+	}
+
+	return &connRow{row: conn.QueryRow(ctx, sql, args...), release: releaseOnce(conn)}
+}
+
+// Begin keeps the connection until the transaction is committed or rolled
+// back.
+func (q poolQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	conn, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return &connTx{Tx: tx, release: releaseOnce(conn)}, nil
+}
+
+func (q poolQuerier) acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	conn, err := Acquire(ctx, q.pool)
+	if err != nil {
+		return nil, AcquireError(err)
+	}
+	return conn, nil
+}
+
+// AcquireError maps an error from Acquire to an AppError: an exhausted pool
+// is SERVICE_UNAVAILABLE, a canceled or expired context keeps its own code
+// and anything else is ACQUIRE_CONN.
+func AcquireError(err error) error {
+	if errors.Is(err, ErrPoolExhausted) {
+		return apperrors.NewAppError(apperrors.CodeUnavailable, "database is busy, retry later", err)
+	}
+	if ctxErr := apperrors.FromContext(err); ctxErr != nil {
+		return ctxErr
+	}
+	return apperrors.NewAppError(codeAcquire, "failed to acquire connection", err)
+}
+
+func releaseOnce(conn *pgxpool.Conn) func() {
+	var once sync.Once
+	return func() { once.Do(conn.Release) }
+}
+
+type connRows struct {
+	pgx.Rows
+	release func()
+}
+
+func (r *connRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *connRows) Close() {
+	r.Rows.Close()
+	r.release()
+}
+
+type connRow struct {
+	row     pgx.Row
+	release func()
+}
+
+func (r *connRow) Scan(dest ...any) error {
+	defer r.release()
+	return r.row.Scan(dest...)
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error { return r.err }
+
+type connTx struct {
+	pgx.Tx
+	release func()
+}
+
+func (tx *connTx) Commit(ctx context.Context) error {
+	defer tx.release()
+	return tx.Tx.Commit(ctx)
+}
+
+func (tx *connTx) Rollback(ctx context.Context) error {
+	defer tx.release()
+	return tx.Tx.Rollback(ctx)
+}
@@ -0,0 +1,136 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	apperrors "marketplace/pkg/errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTinyPool connects a pool of a single connection to TEST_DATABASE_URL
+// and skips the test when it is not set.
+func newTinyPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("parse config: %v", err)
+	}
+	cfg.MaxConns = 1
+	cfg.MinConns = 0
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	previous := acquireTimeout
+	acquireTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { acquireTimeout = previous })
+
+	return pool
+}
+
+func TestQuerierFromReportsExhaustedPool(t *testing.T) {
+	pool := newTinyPool(t)
+	ctx := context.Background()
+
+	held, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	q := QuerierFrom(ctx, pool)
+	checks := map[string]error{}
+	_, checks["exec"] = q.Exec(ctx, "SELECT 1")
+	_, checks["query"] = q.Query(ctx, "SELECT 1")
+	var one int
+	checks["query row"] = q.QueryRow(ctx, "SELECT 1").Scan(&one)
+	_, checks["begin"] = q.Begin(ctx)
+
+	for name, err := range checks {
+		if !errors.Is(err, ErrPoolExhausted) {
+			t.Errorf("%s: error = %v, want ErrPoolExhausted", name, err)
+		}
+		// Repositories wrap the error; the responder must still find it.
+		wrapped := apperrors.NewAppError("EXEC_QUERY", "failed execute query", err)
+		if unavailable := apperrors.UnavailableError(wrapped); unavailable == nil {
+			t.Errorf("%s: no %s error in %v", name, apperrors.CodeUnavailable, wrapped)
+		}
+		if ctxErr := apperrors.ContextError(wrapped); ctxErr != nil {
+			t.Errorf("%s: reported as %s", name, ctxErr.Code())
+		}
+	}
+
+	held.Release()
+	if _, err := q.Exec(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("exec after release: %v", err)
+	}
+}
+
+func TestQuerierFromReleasesConnections(t *testing.T) {
+	pool := newTinyPool(t)
+	ctx := context.Background()
+	q := QuerierFrom(ctx, pool)
+
+	// With a single connection, every call below fails if an earlier one
+	// kept its connection.
+	for i := 0; i < 3; i++ {
+		rows, err := q.Query(ctx, "SELECT generate_series(1, 3)")
+		if err != nil {
+			t.Fatalf("query %d: %v", i, err)
+		}
+		for rows.Next() {
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatalf("rows %d: %v", i, err)
+		}
+
+		var n int
+		if err := q.QueryRow(ctx, "SELECT $1::int", i).Scan(&n); err != nil || n != i {
+			t.Fatalf("query row %d: n = %d, err = %v", i, n, err)
+		}
+
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			t.Fatalf("begin %d: %v", i, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("commit %d: %v", i, err)
+		}
+		// Rolling back after a commit is the usual deferred cleanup and
+		// must not release the connection twice.
+		_ = tx.Rollback(ctx)
+	}
+}
+
+func TestAcquireError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{fmt.Errorf("%w: timeout", ErrPoolExhausted), apperrors.CodeUnavailable},
+		{context.Canceled, apperrors.CodeRequestCanceled},
+		{context.DeadlineExceeded, apperrors.CodeTimeout},
+		{errors.New("connection refused"), codeAcquire},
+	}
+
+	for _, tt := range tests {
+		var appErr *apperrors.AppError
+		if err := AcquireError(tt.err); !errors.As(err, &appErr) || appErr.Code() != tt.code {
+			t.Errorf("AcquireError(%v) = %v, want code %s", tt.err, err, tt.code)
+		}
+	}
+}
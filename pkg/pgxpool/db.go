@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"marketplace/pkg/config"
 	"time"
@@ -10,6 +11,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultMaxConns       = 20
+	defaultMinConns       = 5
+	defaultAcquireTimeout = 3 * time.Second
+)
+
+// ErrPoolExhausted is returned by Acquire when no connection became free
+// within the acquire timeout.
+var ErrPoolExhausted = errors.New("database pool exhausted")
+
+var acquireTimeout = defaultAcquireTimeout
+
 func BuildDSN(cfg *config.Config) string {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		cfg.DB.User,
@@ -31,11 +44,19 @@ func InitDBPool(ctx context.Context, cfg *config.Config, log *logrus.Logger) (*p
 		return nil, fmt.Errorf("failed to parse pool's config: %w", err)
 	}
 
-	poolConfig.MaxConns = 20
-	poolConfig.MinConns = 5
+	maxConns := cfg.DB.MaxConns
+	if maxConns <= 0 {
+		maxConns = defaultMaxConns
+	}
+	poolConfig.MaxConns = maxConns
+	poolConfig.MinConns = min(defaultMinConns, maxConns)
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = time.Minute * 30
 
+	if cfg.DB.AcquireTimeout > 0 {
+		acquireTimeout = cfg.DB.AcquireTimeout
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		log.WithError(err).Error("Failed to initializate database pool")
@@ -55,3 +76,23 @@ func InitDBPool(ctx context.Context, cfg *config.Config, log *logrus.Logger) (*p
 
 	return pool, nil
 }
+
+// Acquire takes a connection from the pool, waiting at most the configured
+// acquire timeout. If the wait times out while the caller's context is still
+// alive, the pool is considered exhausted and ErrPoolExhausted is returned.
+func Acquire(ctx context.Context, pool *pgxpool.Pool) (*pgxpool.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, acquireTimeout)
+	defer cancel()
+
+	conn, err := pool.Acquire(acquireCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			// The deadline is ours, not the caller's, so it is not wrapped
+			// and the error is not mistaken for a request timeout.
+			return nil, fmt.Errorf("%w: %v", ErrPoolExhausted, err)
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
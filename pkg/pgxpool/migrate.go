@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/sirupsen/logrus"
+)
+
+// EnsureMigrated checks that the database schema is at the latest migration
+// found in path. When apply is true pending migrations are run first. It
+// fails if the schema is dirty or behind, so the server never starts against
+// an outdated schema.
+func EnsureMigrated(dsn, path string, apply bool, log *logrus.Logger) error {
+	sourceURL := "file://" + path
+
+	latest, err := latestVersion(sourceURL)
+	if err != nil {
+		return fmt.Errorf("failed to read migrations from %s: %w", path, err)
+	}
+
+	m, err := migrate.New(sourceURL, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to init migrate: %w", err)
+	}
+	defer m.Close()
+
+	if apply {
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("schema is dirty at version %d, fix it manually before starting", version)
+	}
+	if version < latest {
+		return fmt.Errorf("schema is at version %d, expected %d; run migrations first", version, latest)
+	}
+
+	log.WithFields(logrus.Fields{
+		"version": version,
+	}).Info("Database schema is up to date")
+
+	return nil
+}
+
+// latestVersion returns the highest migration version in the source.
+func latestVersion(sourceURL string) (uint, error) {
+	src, err := (&file.File{}).Open(sourceURL)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -20,6 +20,8 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
+var _ Validator = (*customValidator)(nil)
+
 type customValidator struct {
 	validator *validator.Validate
 }
@@ -70,7 +72,13 @@ func (cv *customValidator) ValidateStruct(s interface{}) []ValidationError {
 			case "len":
 				ve.Message = fmt.Sprintf("Field %s must be exactly %s characters", fieldError.Field(), fieldError.Param())
 			case "oneof":
-				ve.Message = fmt.Sprintf("Field %s must be one of: %s", fieldError.Field(), fieldError.Param())
+				ve.Message = fmt.Sprintf("Field %s must be one of: %s", fieldError.Field(), strings.Join(strings.Fields(fieldError.Param()), ", "))
+			case "e164":
+				ve.Message = fmt.Sprintf("Field %s must be a phone number in E.164 format, e.g. +79991234567", fieldError.Field())
+			case "datetime":
+				ve.Message = fmt.Sprintf("Field %s must be a date in %s format", fieldError.Field(), humanLayout(fieldError.Param()))
+			case "url":
+				ve.Message = fmt.Sprintf("Field %s must be a valid URL", fieldError.Field())
 			default:
 				ve.Message = fmt.Sprintf("Field %s failed validation for tag %s", fieldError.Field(), fieldError.Tag())
 			}
@@ -81,3 +89,16 @@ func (cv *customValidator) ValidateStruct(s interface{}) []ValidationError {
 	
 	return validationErrors
 }
+
+// humanLayout converts a Go time layout into the notation users expect,
+// e.g. "2006-01-02" becomes "YYYY-MM-DD".
+func humanLayout(layout string) string {
+	return strings.NewReplacer(
+		"2006", "YYYY",
+		"01", "MM",
+		"02", "DD",
+		"15", "hh",
+		"04", "mm",
+		"05", "ss",
+	).Replace(layout)
+}